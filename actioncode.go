@@ -0,0 +1,186 @@
+package ssimparser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Originator is the side that sends a message: an airline submits requests,
+// a coordinator replies to them.
+type Originator int
+
+const (
+	OriginatorUnknown Originator = iota
+	OriginatorAirline
+	OriginatorCoordinator
+)
+
+func (o Originator) String() string {
+	switch o {
+	case OriginatorUnknown:
+		return "Unknown"
+	case OriginatorAirline:
+		return "Airline"
+	case OriginatorCoordinator:
+		return "Coordinator"
+	default:
+		return fmt.Sprintf("Originator(%d)", int(o))
+	}
+}
+
+// ActionCodeInfo describes an action code.
+// A code used by both sides (P) has a description for each of them.
+type ActionCodeInfo struct {
+	Code        ActionCode
+	Airline     string // meaning in an airline message, empty if airlines do not use the code
+	Coordinator string // meaning in a coordinator message, empty if coordinators do not use the code
+	// Revision is the action code of the line carrying the new state (C -> R, X -> K)
+	Revision ActionCode
+	// RevisionRequired is set when the line must be followed by its revision.
+	// X may stand alone as a deletion acknowledgement.
+	RevisionRequired bool
+}
+
+var actionCodes = map[ActionCode]ActionCodeInfo{
+	ActionNewRequest:      {Code: ActionNewRequest, Airline: "Acceptance of an offer - no further improvement desired"},
+	ActionNewEntrant:      {Code: ActionNewEntrant, Airline: "New entrant"},
+	ActionChangeSlot:      {Code: ActionChangeSlot, Airline: "Slot to be changed", Revision: ActionNewRevised, RevisionRequired: true},
+	ActionDeleteSlot:      {Code: ActionDeleteSlot, Airline: "Delete slot"},
+	ActionEliminateSlot:   {Code: ActionEliminateSlot, Airline: "Eliminate slot"},
+	ActionHistoricUse:     {Code: ActionHistoricUse, Airline: "Historic slot use"},
+	ActionRevisedCont:     {Code: ActionRevisedCont, Airline: "Revised slot - continuation"},
+	ActionRevisedNoOffer:  {Code: ActionRevisedNoOffer, Airline: "Revised slot - no offer acceptable"},
+	ActionNewSlot:         {Code: ActionNewSlot, Airline: "New slot"},
+	ActionNewRevised:      {Code: ActionNewRevised, Airline: "Revised slot - acceptable"},
+	ActionNewEntrantRound: {Code: ActionNewEntrantRound, Airline: "New entrant with year round status"},
+	ActionNewSlotCont:     {Code: ActionNewSlotCont, Airline: "New slot - continuation from previous adjacent season"},
+	ActionDeclineOffer:    {Code: ActionDeclineOffer, Airline: "Decline offer"},
+	ActionPendingSlot: {
+		Code:        ActionPendingSlot,
+		Airline:     "Acceptance of an offer - maintain as outstanding request",
+		Coordinator: "Request pending",
+	},
+	ActionHoldingSlot:   {Code: ActionHoldingSlot, Coordinator: "Holding slot"},
+	ActionConfirmation:  {Code: ActionConfirmation, Coordinator: "New/revised slot confirmation"},
+	ActionOffer:         {Code: ActionOffer, Coordinator: "Offer"},
+	ActionConditionSlot: {Code: ActionConditionSlot, Coordinator: "Allocated slot subject to conditions"},
+	ActionUnableSlot:    {Code: ActionUnableSlot, Coordinator: "Unable to confirm - slot not allocated"},
+	ActionUnableInfo:    {Code: ActionUnableInfo, Coordinator: "Unable to reconcile flight information"},
+	ActionDeleteandAck:  {Code: ActionDeleteandAck, Coordinator: "Slot delete confirmation", Revision: ActionConfirmation},
+}
+
+// ActionCodes returns the registry of known action codes sorted by code
+func ActionCodes() []ActionCodeInfo {
+	infos := make([]ActionCodeInfo, 0, len(actionCodes))
+	for _, info := range actionCodes {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code })
+	return infos
+}
+
+// Info returns the registry entry of a
+func (a ActionCode) Info() (ActionCodeInfo, bool) {
+	info, ok := actionCodes[a]
+	return info, ok
+}
+
+// IsValid reports whether a is one of the airline or coordinator action codes
+func (a ActionCode) IsValid() bool {
+	_, ok := actionCodes[a]
+	return ok
+}
+
+// IsAirline reports whether airlines may send a
+func (a ActionCode) IsAirline() bool {
+	return actionCodes[a].Airline != ""
+}
+
+// IsCoordinator reports whether coordinators may send a
+func (a ActionCode) IsCoordinator() bool {
+	return actionCodes[a].Coordinator != ""
+}
+
+// AllowedFor reports whether a may appear in a message sent by o.
+// OriginatorUnknown allows every valid code.
+func (a ActionCode) AllowedFor(o Originator) bool {
+	switch o {
+	case OriginatorAirline:
+		return a.IsAirline()
+	case OriginatorCoordinator:
+		return a.IsCoordinator()
+	}
+	return a.IsValid()
+}
+
+// Description returns the meaning of a in a message sent by o.
+// For OriginatorUnknown the airline meaning is preferred.
+func (a ActionCode) Description(o Originator) string {
+	info := actionCodes[a]
+	switch o {
+	case OriginatorAirline:
+		return info.Airline
+	case OriginatorCoordinator:
+		return info.Coordinator
+	}
+	if info.Airline != "" {
+		return info.Airline
+	}
+	return info.Coordinator
+}
+
+// Originator returns the only side that may send a, OriginatorUnknown for a code both sides use
+func (a ActionCode) Originator() Originator {
+	switch {
+	case a.IsAirline() && !a.IsCoordinator():
+		return OriginatorAirline
+	case a.IsCoordinator() && !a.IsAirline():
+		return OriginatorCoordinator
+	}
+	return OriginatorUnknown
+}
+
+// Revision returns the action code of the line that carries the new state after a,
+// and whether that line is required
+func (a ActionCode) Revision() (ActionCode, bool) {
+	info := actionCodes[a]
+	return info.Revision, info.RevisionRequired
+}
+
+// DetectOriginator works out who sent msg from its action codes.
+// The reply reference only decides when the codes do not (no items or only P lines),
+// since an airline answering an offer may also quote the coordinator message.
+// A message mixing airline and coordinator codes is OriginatorUnknown.
+func DetectOriginator(msg *SCRMessage) Originator {
+	airline, coordinator := originatorCodes(msg.Items)
+	switch {
+	case len(airline) > 0 && len(coordinator) > 0:
+		return OriginatorUnknown
+	case len(airline) > 0:
+		return OriginatorAirline
+	case len(coordinator) > 0:
+		return OriginatorCoordinator
+	case msg.ReplyReference != nil:
+		return OriginatorCoordinator
+	}
+	return OriginatorUnknown
+}
+
+// originatorCodes returns the distinct codes only an airline and only a coordinator may send, in message order
+func originatorCodes(items []*SlotItem) (airline, coordinator []ActionCode) {
+	seen := make(map[ActionCode]bool)
+	for _, item := range items {
+		code := item.ActionCode
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		switch code.Originator() {
+		case OriginatorAirline:
+			airline = append(airline, code)
+		case OriginatorCoordinator:
+			coordinator = append(coordinator, code)
+		}
+	}
+	return airline, coordinator
+}
@@ -0,0 +1,149 @@
+package ssimparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestActionCodeRegistry(t *testing.T) {
+	codes := ActionCodes()
+	if len(codes) != len(actionCodes) {
+		t.Fatalf("ActionCodes() returned %d codes, want %d", len(codes), len(actionCodes))
+	}
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code >= codes[i].Code {
+			t.Errorf("ActionCodes() not sorted at %v, %v", codes[i-1].Code, codes[i].Code)
+		}
+	}
+	for _, info := range codes {
+		if info.Airline == "" && info.Coordinator == "" {
+			t.Errorf("%v has no description", info.Code)
+		}
+	}
+
+	if code, required := ActionChangeSlot.Revision(); code != ActionNewRevised || !required {
+		t.Errorf("C revision = %v, %v; want R, required", code, required)
+	}
+	if code, required := ActionDeleteandAck.Revision(); code != ActionConfirmation || required {
+		t.Errorf("X revision = %v, %v; want K, optional", code, required)
+	}
+	if code, _ := ActionNewSlot.Revision(); code != "" {
+		t.Errorf("N revision = %v, want none", code)
+	}
+	if ActionCode("Q").IsValid() {
+		t.Error("Q is not an action code")
+	}
+	if got := ActionPendingSlot.Description(OriginatorCoordinator); got != "Request pending" {
+		t.Errorf("P for a coordinator = %q", got)
+	}
+	if got := ActionPendingSlot.Description(OriginatorUnknown); got != ActionPendingSlot.Description(OriginatorAirline) {
+		t.Errorf("P without originator = %q, want the airline meaning", got)
+	}
+}
+
+func TestActionCodeAllowedFor(t *testing.T) {
+	tests := []struct {
+		code                          ActionCode
+		airline, coordinator, unknown bool
+	}{
+		{ActionNewSlot, true, false, true},
+		{ActionChangeSlot, true, false, true},
+		{ActionConfirmation, false, true, true},
+		{ActionDeleteandAck, false, true, true},
+		{ActionPendingSlot, true, true, true},
+		{ActionCode("Q"), false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			if got := tt.code.AllowedFor(OriginatorAirline); got != tt.airline {
+				t.Errorf("AllowedFor(Airline) = %v, want %v", got, tt.airline)
+			}
+			if got := tt.code.AllowedFor(OriginatorCoordinator); got != tt.coordinator {
+				t.Errorf("AllowedFor(Coordinator) = %v, want %v", got, tt.coordinator)
+			}
+			if got := tt.code.AllowedFor(OriginatorUnknown); got != tt.unknown {
+				t.Errorf("AllowedFor(Unknown) = %v, want %v", got, tt.unknown)
+			}
+		})
+	}
+}
+
+func TestParseRejectsCodesOfTheOtherSide(t *testing.T) {
+	message := "SCR\nW25\n15OCT\nKRK\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n"
+	parser := NewScrParser()
+	parser.SetOriginator(OriginatorAirline)
+	_, err := parser.Parse(strings.NewReader(message))
+	if !errors.Is(err, ErrActionNotAllowed) {
+		t.Fatalf("Parse error = %v, want %v", err, ErrActionNotAllowed)
+	}
+	var perr *ParserError
+	if !errors.As(err, &perr) || perr.LineNumber != 5 {
+		t.Errorf("Parse error = %#v, want a *ParserError for line 5", err)
+	}
+
+	parser.SetOriginator(OriginatorCoordinator)
+	if _, err := parser.Parse(strings.NewReader(message)); err != nil {
+		t.Errorf("Parse as coordinator: %v", err)
+	}
+}
+
+func TestDetectOriginator(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Originator
+	}{
+		{"airline only", "SCR\nW25\n15OCT\nKRK\nNLO10 01JAN31JAN 1234567 18973H GOT1105 J\nC LO11 01JAN31JAN 1234567 18973H 1205GOT J\n", OriginatorAirline},
+		{"coordinator only", "SCR\nW25\n15OCT\nKRK\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\nH FR5610 01JAN01JAN 0004000 18973H 1315FMM J\n", OriginatorCoordinator},
+		{"reply with shared codes only", "SCR\nW25\n15OCT\nKRK\nREYT/15OCT25/\nP FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n", OriginatorCoordinator},
+		{"mixed", "SCR\nW25\n15OCT\nKRK\nNLO10 01JAN31JAN 1234567 18973H GOT1105 J\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n", OriginatorUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := NewScrParserWithValidator().Parse(strings.NewReader(tt.message))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := DetectOriginator(parsed); got != tt.want {
+				t.Errorf("DetectOriginator = %v, want %v", got, tt.want)
+			}
+			if parsed.Originator != tt.want {
+				t.Errorf("Originator = %v, want %v", parsed.Originator, tt.want)
+			}
+		})
+	}
+}
+
+func TestMixedActionCodes(t *testing.T) {
+	single := "SCR\nW25\n15OCT\nKRK\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\nH FR5610 01JAN01JAN 0004000 18973H 1315FMM J\n"
+	mixed := "SCR\nW25\n15OCT\nKRK\nNLO10 01JAN31JAN 1234567 18973H GOT1105 J\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n"
+
+	parser := NewScrParserWithValidator()
+	parsed, err := parser.Parse(strings.NewReader(single))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	parser.GetValidator().ValidateSCR(parsed)
+	if issues := parser.GetValidator().Container; len(issues) != 0 {
+		t.Errorf("coordinator only message has issues: %v", issues)
+	}
+
+	parser = NewScrParserWithValidator()
+	parsed, err = parser.Parse(strings.NewReader(mixed))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if issues := parser.GetValidator().Container; len(issues) != 0 {
+		t.Fatalf("Parse issues = %v, want the mix left to ValidateSCR", issues)
+	}
+	if parsed.Originator != OriginatorUnknown {
+		t.Errorf("Originator = %v, want %v for a mixed message", parsed.Originator, OriginatorUnknown)
+	}
+	validator := parser.GetValidator()
+	validator.ValidateSCR(parsed)
+	if len(validator.Container) != 1 || validator.Container[0].Severity != Major ||
+		!strings.Contains(validator.Container[0].Message, "mixes") {
+		t.Errorf("ValidateSCR issues = %v, want the mixed code issue", validator.Container)
+	}
+}
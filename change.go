@@ -0,0 +1,157 @@
+package ssimparser
+
+import "fmt"
+
+// SlotChange links the line describing the current slot with the line carrying its new state
+//
+//	C AB456 26OCT23NOV 0204060 189738 0030KIX J   Old (airline: slot to be changed)
+//	R AB456 26OCT23NOV 0204060 189738 0100KIX J   New (airline: revised slot)
+//
+//	X FR7840 01JAN01JAN 0004000 18973H 1105GOT J  Old (coordinator: slot deleted)
+//	K FR7840 01JAN01JAN 0004000 18973H 1155GOT J  New (coordinator: new slot confirmed)
+//
+// The revision must be the next data line. For turnaround lines both halves are paired by direction.
+// X may stand alone, a following K is only its revision when it keeps the flight.
+// A revision of another direction is still linked and reported by the validator as mismatched.
+type SlotChange struct {
+	Old *SlotItem
+	New *SlotItem
+}
+
+// LinkChanges pairs C/R and X/K lines of items, see SlotChange
+func LinkChanges(items []*SlotItem) []*SlotChange {
+	changes, _ := linkChanges(items)
+	return changes
+}
+
+// linkChanges pairs change lines with their revisions and also returns
+// the items left without a required partner (C without R, R without C)
+func linkChanges(items []*SlotItem) ([]*SlotChange, []*SlotItem) {
+	changes := make([]*SlotChange, 0)
+	orphans := make([]*SlotItem, 0)
+	lines := dataLines(items)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		code := line[0].ActionCode
+		revision, required := code.Revision()
+		if revision == "" {
+			if isRequiredRevision(code) {
+				orphans = append(orphans, line...)
+			}
+			continue
+		}
+		if i+1 == len(lines) || lines[i+1][0].ActionCode != revision {
+			if required {
+				orphans = append(orphans, line...)
+			}
+			continue
+		}
+		if !required && !revisesLine(line, lines[i+1]) {
+			continue
+		}
+		i++
+		next := lines[i]
+		used := make([]bool, len(next))
+		for _, old := range line {
+			j := pairIndex(old, next, used)
+			if j < 0 {
+				orphans = append(orphans, old)
+				continue
+			}
+			used[j] = true
+			changes = append(changes, &SlotChange{Old: old, New: next[j]})
+		}
+		for j, item := range next {
+			if !used[j] && isRequiredRevision(item.ActionCode) {
+				orphans = append(orphans, item)
+			}
+		}
+	}
+	return changes, orphans
+}
+
+// pairIndex picks the revision item for old: same direction first, otherwise the first unused item
+func pairIndex(old *SlotItem, next []*SlotItem, used []bool) int {
+	first := -1
+	for j, item := range next {
+		if used[j] {
+			continue
+		}
+		if item.Direction == old.Direction {
+			return j
+		}
+		if first < 0 {
+			first = j
+		}
+	}
+	return first
+}
+
+// revisesLine reports whether every item of line has an item of the same flight in next
+func revisesLine(line, next []*SlotItem) bool {
+	used := make([]bool, len(next))
+	for _, old := range line {
+		j := pairIndex(old, next, used)
+		if j < 0 || !next[j].Flight.Equal(old.Flight) {
+			return false
+		}
+		used[j] = true
+	}
+	return true
+}
+
+// isRequiredRevision reports whether code may only appear after the line it revises (R after C)
+func isRequiredRevision(code ActionCode) bool {
+	for _, info := range actionCodes {
+		if info.RevisionRequired && info.Revision == code {
+			return true
+		}
+	}
+	return false
+}
+
+// dataLines groups items back into data lines, turnaround halves stored next to each other form one line
+func dataLines(items []*SlotItem) [][]*SlotItem {
+	lines := make([][]*SlotItem, 0, len(items))
+	for i := 0; i < len(items); i++ {
+		if items[i].Turnaround && i+1 < len(items) && items[i+1].Turnaround {
+			lines = append(lines, items[i:i+2])
+			i++
+			continue
+		}
+		lines = append(lines, items[i:i+1])
+	}
+	return lines
+}
+
+// validateChanges adds a Major issue for every orphaned or mismatched change pair
+func (pv *ParsingValidator) validateChanges(items []*SlotItem) {
+	changes, orphans := linkChanges(items)
+	for _, item := range orphans {
+		msg := fmt.Sprintf("%v line without its %v partner", item.ActionCode, changePartner(item.ActionCode))
+		pv.AddError(NewParserError(msg, item.LineNumber, item.RawDataLine, nil, Major))
+	}
+	for _, change := range changes {
+		if !change.Old.Flight.Equal(change.New.Flight) {
+			msg := fmt.Sprintf("%v/%v pair changes flight %v to %v", change.Old.ActionCode, change.New.ActionCode, change.Old.Flight, change.New.Flight)
+			pv.AddError(NewParserError(msg, change.New.LineNumber, change.New.RawDataLine, nil, Major))
+		}
+		if change.Old.Direction != change.New.Direction {
+			msg := fmt.Sprintf("%v/%v pair changes direction of %v", change.Old.ActionCode, change.New.ActionCode, change.Old.Flight)
+			pv.AddError(NewParserError(msg, change.New.LineNumber, change.New.RawDataLine, nil, Major))
+		}
+	}
+}
+
+// changePartner returns the code the line should be paired with
+func changePartner(code ActionCode) ActionCode {
+	if revision, _ := code.Revision(); revision != "" {
+		return revision
+	}
+	for _, info := range actionCodes {
+		if info.Revision == code {
+			return info.Code
+		}
+	}
+	return ""
+}
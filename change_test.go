@@ -0,0 +1,76 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkChanges(t *testing.T) {
+	const header = "SCR\nW25\n15OCT\nKRK\n"
+	tests := []struct {
+		name    string
+		lines   string
+		changes []string // old and new flight of every change
+		issues  []string // validator issues, all Major
+	}{
+		{
+			name:    "airline change pair",
+			lines:   "C LO012 26OCT23NOV 0204060 189738 0030KIX J\nR LO012 26OCT23NOV 0204060 189738 0100KIX J\n",
+			changes: []string{"LO012>LO012"},
+		},
+		{
+			name:    "coordinator retime",
+			lines:   "X FR7840 01JAN01JAN 0004000 18973H 1105GOT J\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n",
+			changes: []string{"FR7840>FR7840"},
+		},
+		{
+			name:  "deletion followed by an unrelated confirmation",
+			lines: "X LO016 01JAN01JAN 0004000 18973H 1105GOT J\nK LO018 01JAN01JAN 0004000 18973H 1155GOT J\n",
+		},
+		{
+			name:    "coordinator revision of the other direction",
+			lines:   "X LO016 01JAN01JAN 0004000 18973H 1105GOT J\nKLO016 01JAN01JAN 0004000 18973H GOT1155 J\n",
+			changes: []string{"LO016>LO016"},
+			issues:  []string{"X/K pair changes direction of LO016"},
+		},
+		{
+			name:    "airline revision of the other direction",
+			lines:   "C LO012 26OCT23NOV 0204060 189738 0030KIX J\nRLO012 26OCT23NOV 0204060 189738 KIX0100 J\n",
+			changes: []string{"LO012>LO012"},
+			issues:  []string{"C/R pair changes direction of LO012"},
+		},
+		{
+			name:    "revision of another flight",
+			lines:   "C LO012 26OCT23NOV 0204060 189738 0030KIX J\nR LO014 26OCT23NOV 0204060 189738 0100KIX J\n",
+			changes: []string{"LO012>LO014"},
+			issues:  []string{"C/R pair changes flight LO012 to LO014"},
+		},
+		{
+			name:   "change without revision",
+			lines:  "C LO012 26OCT23NOV 0204060 189738 0030KIX J\n",
+			issues: []string{"C line without its R partner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := parseSCR(t, header+tt.lines)
+			got := make([]string, 0)
+			for _, change := range message.Changes {
+				got = append(got, change.Old.Flight.String()+">"+change.New.Flight.String())
+			}
+			if strings.Join(got, " ") != strings.Join(tt.changes, " ") {
+				t.Errorf("changes = %v, want %v", got, tt.changes)
+			}
+			validator := NewParsingValidator()
+			validator.validateChanges(message.Items)
+			if len(validator.Container) != len(tt.issues) {
+				t.Fatalf("validator issues = %v, want %q", validator.Container, tt.issues)
+			}
+			for i, issue := range validator.Container {
+				if issue.Message != tt.issues[i] || issue.Severity != Major {
+					t.Errorf("issue %d = %q %v, want %q Major", i, issue.Message, issue.Severity, tt.issues[i])
+				}
+			}
+		})
+	}
+}
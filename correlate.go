@@ -0,0 +1,183 @@
+package ssimparser
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Correlating an airline request with the coordinator replies
+//
+//	request:  C AB456 26OCT23NOV 0204060 189738 0030KIX J
+//	          R AB456 26OCT23NOV 0204060 189738 0100KIX J
+//	reply:    H AB456 26OCT23NOV 0204060 189738 0030KIX J   (old slot held)
+//	          O AB456 26OCT23NOV 0204060 189738 0115KIX J   (offer 15 minutes later)
+//
+// Every request item is matched on flight designator, direction and an overlapping period.
+// The old (C) half of a change pairs with the X/H reply line, the new (R) half with K/O/U.
+
+type ReplyStatus int
+
+const (
+	StatusOutstanding ReplyStatus = iota // no decisive answer yet (no reply or P pending)
+	StatusConfirmed                      // K or T, X for a deletion
+	StatusOffered                        // O, see Correlation.TimeDelta
+	StatusRefused                        // U or W, H for a change that keeps the old slot
+)
+
+func (s ReplyStatus) String() string {
+	switch s {
+	case StatusOutstanding:
+		return "Outstanding"
+	case StatusConfirmed:
+		return "Confirmed"
+	case StatusOffered:
+		return "Offered"
+	case StatusRefused:
+		return "Refused"
+	default:
+		return "ReplyStatus(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// Correlation is one requested movement with the coordinator response items
+type Correlation struct {
+	Request   *SlotItem   // requested item, the R half for a C/R change
+	Previous  *SlotItem   // C half of a C/R change, nil otherwise
+	Responses []*SlotItem // reply items answering the request, in message order
+	Status    ReplyStatus
+	// TimeDelta is the offered minus the requested time, set for StatusOffered
+	TimeDelta time.Duration
+}
+
+// Correlate pairs every item of the airline request with the coordinator reply items.
+// Replies are applied in order, the status comes from the last reply that answers the item.
+// A reply whose reply reference points at another message is ignored, as is a nil reply.
+func Correlate(request *SCRMessage, replies ...*SCRMessage) ([]*Correlation, error) {
+	if request == nil {
+		return nil, fmt.Errorf("ssimparser: cannot correlate nil request")
+	}
+	correlations := requestCorrelations(request.Items)
+
+	for _, reply := range replies {
+		if reply == nil || (reply.ReplyReference != nil && !reply.IsReplyTo(request)) {
+			continue
+		}
+		answered := make(map[*Correlation][]*SlotItem)
+		for _, item := range reply.Items {
+			if c := matchCorrelation(correlations, item); c != nil {
+				answered[c] = append(answered[c], item)
+			}
+		}
+		for c, items := range answered {
+			c.Responses = append(c.Responses, items...)
+			c.Status, c.TimeDelta = replyStatus(c, items)
+		}
+	}
+	return correlations, nil
+}
+
+// requestCorrelations builds one correlation per requested movement,
+// a C/R change pair (see LinkChanges) is a single correlation
+func requestCorrelations(items []*SlotItem) []*Correlation {
+	revised := make(map[*SlotItem]*SlotItem)
+	revisions := make(map[*SlotItem]bool)
+	for _, change := range LinkChanges(items) {
+		revised[change.Old] = change.New
+		revisions[change.New] = true
+	}
+	correlations := make([]*Correlation, 0, len(items))
+	for _, item := range items {
+		switch {
+		case revisions[item]:
+			continue
+		case revised[item] != nil:
+			correlations = append(correlations, &Correlation{Request: revised[item], Previous: item})
+		default:
+			correlations = append(correlations, &Correlation{Request: item})
+		}
+	}
+	return correlations
+}
+
+// matchCorrelation finds the request answered by a reply item.
+// X and H lines echo the old state so they are matched against the C half first.
+// A correlation with the same period wins over one that only overlaps.
+func matchCorrelation(correlations []*Correlation, item *SlotItem) *Correlation {
+	echoesOld := item.ActionCode == ActionDeleteandAck || item.ActionCode == ActionHoldingSlot
+	var best *Correlation
+	bestScore := 0
+	for _, c := range correlations {
+		target := c.Request
+		if echoesOld && c.Previous != nil {
+			target = c.Previous
+		}
+		if !target.Flight.Equal(item.Flight) || target.Direction != item.Direction {
+			continue
+		}
+		if score := periodMatch(target.PeriodOfOperation, item.PeriodOfOperation); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// periodMatch scores two periods: 2 identical, 1 overlapping, 0 disjoint.
+// A missing period matches anything.
+func periodMatch(a, b *PeriodOfOperation) int {
+	if a == nil || b == nil {
+		return 1
+	}
+	if a.Effective.Equal(b.Effective) && a.Termination.Equal(b.Termination) {
+		return 2
+	}
+	if a.Effective.After(b.Termination) || b.Effective.After(a.Termination) {
+		return 0
+	}
+	return 1
+}
+
+// replyStatus decides the outcome from the items of one reply
+func replyStatus(c *Correlation, items []*SlotItem) (ReplyStatus, time.Duration) {
+	has := make(map[ActionCode]*SlotItem)
+	for _, item := range items {
+		if _, ok := has[item.ActionCode]; !ok {
+			has[item.ActionCode] = item
+		}
+	}
+	switch {
+	case has[ActionConfirmation] != nil || has[ActionConditionSlot] != nil:
+		return StatusConfirmed, 0
+	case has[ActionOffer] != nil:
+		return StatusOffered, timeDelta(c.Request, has[ActionOffer])
+	case has[ActionUnableSlot] != nil || has[ActionUnableInfo] != nil:
+		return StatusRefused, 0
+	case has[ActionDeleteandAck] != nil && c.Request.ActionCode == ActionDeleteSlot:
+		return StatusConfirmed, 0
+	case has[ActionHoldingSlot] != nil && c.Previous != nil:
+		return StatusRefused, 0
+	}
+	return StatusOutstanding, 0
+}
+
+// timeDelta returns offered minus requested scheduled time, overnight indicators included
+func timeDelta(requested, offered *SlotItem) time.Duration {
+	from, ok := scheduledMinutes(requested)
+	if !ok {
+		return 0
+	}
+	to, ok := scheduledMinutes(offered)
+	if !ok {
+		return 0
+	}
+	return time.Duration(to-from) * time.Minute
+}
+
+func scheduledMinutes(s *SlotItem) (int, bool) {
+	if !isTimeHHMM(s.ScheduledTime) {
+		return 0, false
+	}
+	hh, _ := strconv.Atoi(s.ScheduledTime[:2])
+	mm, _ := strconv.Atoi(s.ScheduledTime[2:])
+	return s.DayChangeIndicator*24*60 + hh*60 + mm, true
+}
@@ -0,0 +1,101 @@
+package ssimparser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCorrelate(t *testing.T) {
+	request := parseSCR(t, "SCR\n/REQ1\nW25\n15OCT\nKIX\n"+
+		"C AB456 26OCT23NOV 0204060 189738 0030KIX J\n"+
+		"R AB456 26OCT23NOV 0204060 189738 0100KIX J\n"+
+		"N AB460 26OCT23NOV 0204060 189738 0800KIX J\n"+
+		"N AB462 26OCT23NOV 0204060 189738 0900KIX J\n")
+	reply := parseSCR(t, "SCR\nW25\n16OCT\nKIX\nREYT/15OCT25/REQ1\n"+
+		"H AB456 26OCT23NOV 0204060 189738 0030KIX J\n"+
+		"O AB456 26OCT23NOV 0204060 189738 0115KIX J\n"+
+		"K AB460 26OCT23NOV 0204060 189738 0800KIX J\n")
+	other := parseSCR(t, "SCR\nW25\n16OCT\nKIX\nREYT/15OCT25/REQ9\nU AB462 26OCT23NOV 0204060 189738 0900KIX J\n")
+
+	correlations, err := Correlate(request, reply, other, nil)
+	if err != nil {
+		t.Fatalf("Correlate: %v", err)
+	}
+	want := []struct {
+		flight string
+		status ReplyStatus
+		delta  time.Duration
+	}{
+		{"AB456", StatusOffered, 15 * time.Minute},
+		{"AB460", StatusConfirmed, 0},
+		{"AB462", StatusOutstanding, 0},
+	}
+	if len(correlations) != len(want) {
+		t.Fatalf("got %d correlations, want %d", len(correlations), len(want))
+	}
+	for i, w := range want {
+		c := correlations[i]
+		if c.Request.Flight.String() != w.flight || c.Status != w.status || c.TimeDelta != w.delta {
+			t.Errorf("#%d: %v %v %v, want %v %v %v", i, c.Request.Flight, c.Status, c.TimeDelta, w.flight, w.status, w.delta)
+		}
+	}
+
+	if _, err := Correlate(nil, reply); err == nil {
+		t.Error("Correlate(nil, reply) succeeded")
+	}
+}
+
+func TestCorrelateRefusalsAndDeletions(t *testing.T) {
+	request := parseSCR(t, "SCR\n/REQ2\nW25\n15OCT\nKIX\n"+
+		"C AB456 26OCT23NOV 0204060 189738 0030KIX J\n"+
+		"R AB456 26OCT23NOV 0204060 189738 0100KIX J\n"+
+		"C AB458 26OCT23NOV 0204060 189738 0200KIX J\n"+
+		"R AB458 26OCT23NOV 0204060 189738 0230KIX J\n"+
+		"D AB460 26OCT23NOV 0204060 189738 0800KIX J\n"+
+		"N AB462 26OCT23NOV 0204060 189738 0900KIX J\n"+
+		"N AB464 26OCT23NOV 0204060 189738 1000KIX J\n")
+	reply := parseSCR(t, "SCR\nW25\n16OCT\nKIX\nREYT/15OCT25/REQ2\n"+
+		"X AB456 26OCT23NOV 0204060 189738 0030KIX J\n"+
+		"K AB456 26OCT23NOV 0204060 189738 0100KIX J\n"+
+		"H AB458 26OCT23NOV 0204060 189738 0200KIX J\n"+
+		"U AB458 26OCT23NOV 0204060 189738 0230KIX J\n"+
+		"X AB460 26OCT23NOV 0204060 189738 0800KIX J\n"+
+		"U AB462 26OCT23NOV 0204060 189738 0900KIX J\n"+
+		"W AB464 26OCT23NOV 0204060 189738 1000KIX J\n")
+
+	correlations, err := Correlate(request, reply)
+	if err != nil {
+		t.Fatalf("Correlate: %v", err)
+	}
+	want := []struct {
+		flight    string
+		status    ReplyStatus
+		responses []ActionCode
+	}{
+		{"AB456", StatusConfirmed, []ActionCode{ActionDeleteandAck, ActionConfirmation}},
+		{"AB458", StatusRefused, []ActionCode{ActionHoldingSlot, ActionUnableSlot}},
+		{"AB460", StatusConfirmed, []ActionCode{ActionDeleteandAck}},
+		{"AB462", StatusRefused, []ActionCode{ActionUnableSlot}},
+		{"AB464", StatusRefused, []ActionCode{ActionUnableInfo}},
+	}
+	if len(correlations) != len(want) {
+		t.Fatalf("got %d correlations, want %d", len(correlations), len(want))
+	}
+	for i, w := range want {
+		c := correlations[i]
+		if c.Request.Flight.String() != w.flight || c.Status != w.status {
+			t.Errorf("#%d: %v %v, want %v %v", i, c.Request.Flight, c.Status, w.flight, w.status)
+		}
+		codes := make([]ActionCode, 0, len(c.Responses))
+		for _, response := range c.Responses {
+			codes = append(codes, response.ActionCode)
+		}
+		if !reflect.DeepEqual(codes, w.responses) {
+			t.Errorf("#%d %v: responses %v, want %v", i, w.flight, codes, w.responses)
+		}
+	}
+	if previous := correlations[0].Previous; previous == nil || previous.ScheduledTime != "0030" {
+		t.Errorf("AB456 previous = %v, want the C half", previous)
+	}
+}
@@ -0,0 +1,155 @@
+package ssimparser
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// DaysOfWeek is the set of weekdays a series operates on.
+// In SSIM notation days are numbered 1 (Monday) to 7 (Sunday) and written positionally,
+// with 0 for a non-operating day e.g. 1030507 is Monday, Wednesday, Friday and Sunday.
+type DaysOfWeek uint8
+
+const (
+	Monday DaysOfWeek = 1 << iota
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+	Sunday
+
+	NoDays  DaysOfWeek = 0
+	AllDays DaysOfWeek = Monday | Tuesday | Wednesday | Thursday | Friday | Saturday | Sunday
+)
+
+// ParseDaysOfWeek parses the positional SSIM days of operation e.g. "1234567" or "0204060".
+// Each position holds either its own day number or 0. A space is accepted in place of 0
+// as used in fixed-width SSIM records.
+func ParseDaysOfWeek(s string) (DaysOfWeek, error) {
+	if len(s) != 7 {
+		return NoDays, fmt.Errorf("%w: invalid days of operation length, expected 7 characters but have %v", ErrBadDaysOfOperation, s)
+	}
+	var days DaysOfWeek
+	for i := 0; i < 7; i++ {
+		switch s[i] {
+		case byte('1' + i):
+			days |= 1 << i
+		case '0', ' ':
+		default:
+			return NoDays, fmt.Errorf("%w: invalid days of operation, expected %c or 0 at position %d but have %v", ErrBadDaysOfOperation, '1'+i, i+1, s)
+		}
+	}
+	return days, nil
+}
+
+// DayOf returns the single-day set for a time.Weekday
+func DayOf(day time.Weekday) DaysOfWeek {
+	// time.Weekday starts on Sunday = 0, SSIM on Monday = 1
+	return 1 << ((int(day) + 6) % 7)
+}
+
+// String returns the positional SSIM form e.g. "1030507"
+func (d DaysOfWeek) String() string {
+	var sb strings.Builder
+	for i := 0; i < 7; i++ {
+		if d&(1<<i) != 0 {
+			sb.WriteByte(byte('1' + i))
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// Has reports whether the set contains the weekday
+func (d DaysOfWeek) Has(day time.Weekday) bool {
+	return d&DayOf(day) != 0
+}
+
+// Operates reports whether the series operates on the date of t
+func (d DaysOfWeek) Operates(t time.Time) bool {
+	return d.Has(t.Weekday())
+}
+
+// Numbers returns the SSIM day numbers (1 = Monday .. 7 = Sunday) in the set
+func (d DaysOfWeek) Numbers() []int {
+	numbers := make([]int, 0, 7)
+	for i := 0; i < 7; i++ {
+		if d&(1<<i) != 0 {
+			numbers = append(numbers, i+1)
+		}
+	}
+	return numbers
+}
+
+// Count returns the number of days in the set
+func (d DaysOfWeek) Count() int {
+	return bits.OnesCount8(uint8(d & AllDays))
+}
+
+// IsEmpty reports whether no day is set
+func (d DaysOfWeek) IsEmpty() bool {
+	return d&AllDays == 0
+}
+
+// Union returns the days in d or other
+func (d DaysOfWeek) Union(other DaysOfWeek) DaysOfWeek {
+	return (d | other) & AllDays
+}
+
+// Intersect returns the days in both d and other
+func (d DaysOfWeek) Intersect(other DaysOfWeek) DaysOfWeek {
+	return d & other & AllDays
+}
+
+// Difference returns the days in d that are not in other
+func (d DaysOfWeek) Difference(other DaysOfWeek) DaysOfWeek {
+	return d &^ other & AllDays
+}
+
+// OperatingDates enumerates every date within the resolved period on which days operate.
+// It returns nil for a nil period.
+func OperatingDates(period *PeriodOfOperation, days DaysOfWeek) []time.Time {
+	return operatingDates(period, days, 1, 0)
+}
+
+// OperatingDates enumerates every concrete date of the slot series at the coordinated airport.
+// A frequency rate above 1 keeps only every n-th week counted from the week of the effective date.
+// The departure half of a turnaround is moved by its overnight indicator, since the period and days
+// of a turnaround line refer to the arrival.
+func (s SlotItem) OperatingDates() []time.Time {
+	shift := 0
+	if s.Turnaround && s.Direction == DirectionDeparture {
+		shift = s.DayChangeIndicator
+	}
+	return operatingDates(s.PeriodOfOperation, s.DaysOfOperation, s.FrequencyRate, shift)
+}
+
+func operatingDates(period *PeriodOfOperation, days DaysOfWeek, rate int, shift int) []time.Time {
+	if period == nil {
+		return nil
+	}
+	if rate < 1 {
+		rate = 1
+	}
+	firstWeek := weekStart(period.Effective)
+	dates := make([]time.Time, 0)
+	for date := period.Effective; !date.After(period.Termination); date = date.AddDate(0, 0, 1) {
+		if !days.Operates(date) {
+			continue
+		}
+		if week := DaysBetween(weekStart(date), firstWeek) / 7; week%rate != 0 {
+			continue
+		}
+		dates = append(dates, date.AddDate(0, 0, shift))
+	}
+	return dates
+}
+
+// weekStart returns the Monday of the SSIM week containing t
+func weekStart(t time.Time) time.Time {
+	return truncateToDate(t).AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
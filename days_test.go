@@ -0,0 +1,138 @@
+package ssimparser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDaysOfWeek(t *testing.T) {
+	tests := []struct {
+		in   string
+		want DaysOfWeek
+		ok   bool
+	}{
+		{"1234567", AllDays, true},
+		{"0000000", NoDays, true},
+		{"1030507", Monday | Wednesday | Friday | Sunday, true},
+		{"0204060", Tuesday | Thursday | Saturday, true},
+		{" 2 4 6 ", Tuesday | Thursday | Saturday, true},
+		{"1 3    ", Monday | Wednesday, true},
+		{"2134567", NoDays, false},
+		{"123456", NoDays, false},
+		{"12345678", NoDays, false},
+		{"12X4567", NoDays, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDaysOfWeek(tt.in)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Errorf("ParseDaysOfWeek(%q) = %v, %v; want %v, ok %v", tt.in, got, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDaysOfWeekString(t *testing.T) {
+	days, err := ParseDaysOfWeek(" 2 4 6 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := days.String(); got != "0204060" {
+		t.Errorf("String() = %q, want 0204060", got)
+	}
+}
+
+func TestDaysOfWeekSetOperations(t *testing.T) {
+	weekdays := Monday | Tuesday | Wednesday | Thursday | Friday
+	weekend := Saturday | Sunday
+	tests := []struct {
+		name string
+		got  DaysOfWeek
+		want DaysOfWeek
+	}{
+		{"union", weekdays.Union(weekend), AllDays},
+		{"union overlapping", (Monday | Tuesday).Union(Tuesday | Friday), Monday | Tuesday | Friday},
+		{"intersect", weekdays.Intersect(Friday | Saturday), Friday},
+		{"intersect disjoint", weekdays.Intersect(weekend), NoDays},
+		{"difference", AllDays.Difference(weekend), weekdays},
+		{"difference of itself", weekend.Difference(weekend), NoDays},
+		{"union ignores unused bit", DaysOfWeek(0xFF).Union(NoDays), AllDays},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperatingDates(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2023, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	period, err := POOFromStringInSeason("26MAR09APR", Season{Type: Summer, Year: 2023})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		period *PeriodOfOperation
+		days   DaysOfWeek
+		want   []time.Time
+	}{
+		{"nil period", nil, AllDays, nil},
+		{"no days", period, NoDays, []time.Time{}},
+		{"sundays", period, Sunday, []time.Time{date(time.March, 26), date(time.April, 2), date(time.April, 9)}},
+		{"monday and wednesday", period, Monday | Wednesday, []time.Time{
+			date(time.March, 27), date(time.March, 29), date(time.April, 3), date(time.April, 5),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OperatingDates(tt.period, tt.days)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OperatingDates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotItemOperatingDatesRateAndOvernight(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	period, err := POOFromStringInSeason("07APR04MAY", Season{Type: Summer, Year: 2025})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mondays := []time.Time{date(time.April, 7), date(time.April, 14), date(time.April, 21), date(time.April, 28)}
+	tests := []struct {
+		name string
+		item SlotItem
+		want []time.Time
+	}{
+		{"weekly", SlotItem{DaysOfOperation: Monday}, mondays},
+		{"fortnightly", SlotItem{DaysOfOperation: Monday, FrequencyRate: 2},
+			[]time.Time{date(time.April, 7), date(time.April, 21)}},
+		{"every third week", SlotItem{DaysOfOperation: Monday, FrequencyRate: 3},
+			[]time.Time{date(time.April, 7), date(time.April, 28)}},
+		{"turnaround arrival", SlotItem{DaysOfOperation: Monday, Turnaround: true, Direction: DirectionArrival,
+			DayChangeIndicator: 1}, mondays},
+		{"turnaround departure next day", SlotItem{DaysOfOperation: Monday, Turnaround: true, Direction: DirectionDeparture,
+			DayChangeIndicator: 1}, []time.Time{date(time.April, 8), date(time.April, 15), date(time.April, 22), date(time.April, 29)}},
+		{"fortnightly departure two days later", SlotItem{DaysOfOperation: Monday, FrequencyRate: 2, Turnaround: true,
+			Direction: DirectionDeparture, DayChangeIndicator: 2}, []time.Time{date(time.April, 9), date(time.April, 23)}},
+		{"single departure ignores indicator", SlotItem{DaysOfOperation: Monday, Direction: DirectionDeparture,
+			DayChangeIndicator: 1}, mondays},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.PeriodOfOperation = period
+			if got := tt.item.OperatingDates(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OperatingDates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package ssimparser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FlightDesignator identifies a flight: airline designator, flight number and optional operational suffix.
+//
+//	LO10    -> LO, 10
+//	BA1234  -> BA, 1234
+//	FR7840A -> FR, 7840, A
+//	9W123   -> 9W, 123
+//	EZY8123 -> EZY, 8123
+type FlightDesignator struct {
+	Airline string // 2-character IATA (may contain one digit e.g. 9W, 3U) or 3-letter ICAO designator
+	Number  string // 1-4 digits as written in the message
+	Suffix  string // optional operational suffix letter
+}
+
+// ParseFlightDesignator parses a flight designator as written on a data line
+func ParseFlightDesignator(s string) (FlightDesignator, error) {
+	if len(s) < 3 {
+		return FlightDesignator{}, fmt.Errorf("%w: %v is too short", ErrBadFlightDesignator, s)
+	}
+
+	// A flight number starts with a digit, so a letter in the third position means a 3-letter airline
+	airlineLength := 2
+	if isUpperLetter(s[2]) {
+		airlineLength = 3
+	}
+	airline := s[:airlineLength]
+	if !isAirlineDesignator(airline) {
+		return FlightDesignator{}, fmt.Errorf("%w: invalid airline designator %v in %v", ErrBadFlightDesignator, airline, s)
+	}
+
+	rest := s[airlineLength:]
+	digits := 0
+	for digits < len(rest) && isDigit(rest[digits]) {
+		digits++
+	}
+	if digits < 1 || digits > 4 {
+		return FlightDesignator{}, fmt.Errorf("%w: expected 1-4 digit flight number in %v", ErrBadFlightDesignator, s)
+	}
+	// there is no flight 0, this also rejects ambiguous input like L10 (L1 flight 0)
+	if n, _ := strconv.Atoi(rest[:digits]); n == 0 {
+		return FlightDesignator{}, fmt.Errorf("%w: flight number must not be zero in %v", ErrBadFlightDesignator, s)
+	}
+	suffix := rest[digits:]
+	if len(suffix) > 1 || (len(suffix) == 1 && !isUpperLetter(suffix[0])) {
+		return FlightDesignator{}, fmt.Errorf("%w: invalid operational suffix %v in %v", ErrBadFlightDesignator, suffix, s)
+	}
+
+	return FlightDesignator{Airline: airline, Number: rest[:digits], Suffix: suffix}, nil
+}
+
+// String returns the designator as it is written on a data line e.g. FR7840A
+func (f FlightDesignator) String() string {
+	return f.Airline + f.Number + f.Suffix
+}
+
+// PaddedNumber returns the flight number zero padded to four digits followed by the suffix e.g. 0010, 7840A
+func (f FlightDesignator) PaddedNumber() string {
+	n, err := strconv.Atoi(f.Number)
+	if err != nil {
+		return f.Number + f.Suffix
+	}
+	return fmt.Sprintf("%04d%s", n, f.Suffix)
+}
+
+// Equal reports whether both designators identify the same flight, ignoring leading zeros in the number
+func (f FlightDesignator) Equal(other FlightDesignator) bool {
+	return f.Airline == other.Airline && f.PaddedNumber() == other.PaddedNumber()
+}
+
+// isAirlineDesignator accepts 2 alphanumeric characters with at least one letter, or 3 letters
+func isAirlineDesignator(s string) bool {
+	letters := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case isUpperLetter(s[i]):
+			letters++
+		case !isDigit(s[i]):
+			return false
+		}
+	}
+	switch len(s) {
+	case 2:
+		return letters >= 1
+	case 3:
+		return letters == 3
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package ssimparser
+
+import "testing"
+
+func TestParseFlightDesignator(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FlightDesignator
+		ok   bool
+	}{
+		{"9W123", FlightDesignator{Airline: "9W", Number: "123"}, true},
+		{"3U8881", FlightDesignator{Airline: "3U", Number: "8881"}, true},
+		{"LO10", FlightDesignator{Airline: "LO", Number: "10"}, true},
+		{"LO010", FlightDesignator{Airline: "LO", Number: "010"}, true},
+		{"BA1234", FlightDesignator{Airline: "BA", Number: "1234"}, true},
+		{"FR7840A", FlightDesignator{Airline: "FR", Number: "7840", Suffix: "A"}, true},
+		{"EZY8123", FlightDesignator{Airline: "EZY", Number: "8123"}, true},
+		{"LO1", FlightDesignator{Airline: "LO", Number: "1"}, true},
+		{"BA12345", FlightDesignator{}, false},
+		{"L10", FlightDesignator{}, false},
+		{"LO0", FlightDesignator{}, false},
+		{"L1", FlightDesignator{}, false},
+		{"99123", FlightDesignator{}, false},
+		{"LO", FlightDesignator{}, false},
+		{"FR7840AB", FlightDesignator{}, false},
+		{"FR78X0", FlightDesignator{}, false},
+		{"E1Z123", FlightDesignator{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseFlightDesignator(tt.in)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Errorf("ParseFlightDesignator(%q) = %+v, %v; want %+v, ok %v", tt.in, got, err, tt.want, tt.ok)
+			}
+			if tt.ok && got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestFlightDesignatorPaddedNumber(t *testing.T) {
+	tests := []struct {
+		in   FlightDesignator
+		want string
+	}{
+		{FlightDesignator{Airline: "LO", Number: "10"}, "0010"},
+		{FlightDesignator{Airline: "FR", Number: "7840", Suffix: "A"}, "7840A"},
+		{FlightDesignator{Airline: "9W", Number: "1"}, "0001"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.PaddedNumber(); got != tt.want {
+			t.Errorf("%v.PaddedNumber() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if !(FlightDesignator{Airline: "LO", Number: "10"}).Equal(FlightDesignator{Airline: "LO", Number: "010"}) {
+		t.Error("LO10 and LO010 should be equal")
+	}
+	if (FlightDesignator{Airline: "LO", Number: "10"}).Equal(FlightDesignator{Airline: "LO", Number: "10", Suffix: "A"}) {
+		t.Error("LO10 and LO10A should differ")
+	}
+}
@@ -0,0 +1,247 @@
+package ssimparser
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Diff compares two schedules for the same airport and returns the SCR that turns previous into planned:
+//
+//	D lines for dates only operated in previous
+//	N lines for dates only operated in planned
+//	C/R pairs for dates operated in both with different times, equipment, service type or stations
+//
+// Schedules are compared date by date per flight and direction, so a change that only affects
+// part of a series produces lines for exactly that part. Items that do not hold a slot
+// (e.g. C, D or U lines) are ignored. Turnarounds are written as separate arrival and departure lines,
+// a departure that had an overnight indicator on the dates it actually operates.
+func Diff(previous, planned *SCRMessage) (*SCRMessage, error) {
+	if previous == nil || planned == nil {
+		return nil, fmt.Errorf("ssimparser: cannot diff nil schedule")
+	}
+	if previous.AirportCode != "" && planned.AirportCode != "" && previous.AirportCode != planned.AirportCode {
+		return nil, fmt.Errorf("ssimparser: cannot diff schedules of different airports %v and %v", previous.AirportCode, planned.AirportCode)
+	}
+	before, after := newScheduleIndex(previous.Items), newScheduleIndex(planned.Items)
+	beforeRates, afterRates := frequencyRates(previous.Items), frequencyRates(planned.Items)
+
+	message := &SCRMessage{
+		Identifier:          "SCR",
+		Season:              planned.Season,
+		MessageDate:         planned.MessageDate,
+		AirportCode:         planned.AirportCode,
+		Originator:          OriginatorAirline,
+		AdministrativeLines: make([]string, 0),
+		Items:               make([]*SlotItem, 0),
+	}
+	if message.Season.IsZero() {
+		message.Season = previous.Season
+	}
+	if message.AirportCode == "" {
+		message.AirportCode = previous.AirportCode
+	}
+
+	for _, key := range mergedKeys(before, after) {
+		message.Items = append(message.Items, diffMovement(key.Direction, before[key], after[key], beforeRates[key], afterRates[key])...)
+	}
+	for _, item := range message.Items {
+		item.SlotKey = item.GetSlotKey()
+	}
+	message.Changes = LinkChanges(message.Items)
+	return message, nil
+}
+
+// scheduleIndex holds the operated attributes of every flight and direction per date
+type scheduleIndex map[scheduleKey]map[time.Time]scheduleEntry
+
+type scheduleKey struct {
+	Airline   string
+	Number    string // padded number with suffix, see FlightDesignator.PaddedNumber
+	Direction Direction
+}
+
+// scheduleEntry is what a single date of a movement looks like.
+// Entries compare equal when nothing on the data line would change.
+type scheduleEntry struct {
+	Flight             FlightDesignator
+	ScheduledTime      string
+	DayChangeIndicator int
+	AircraftType       string
+	Configuration      string
+	ServiceType        ServiceType
+	Station            string
+	AdjacentStation    string
+	ClearanceAirport   string
+}
+
+func newScheduleIndex(items []*SlotItem) scheduleIndex {
+	index := make(scheduleIndex)
+	for _, item := range items {
+		index.add(item)
+	}
+	return index
+}
+
+// add records the dates of item, a later item overrides an earlier one on the same date
+func (index scheduleIndex) add(item *SlotItem) {
+	if !holdsSlot(item.ActionCode) {
+		return
+	}
+	key := scheduleKey{Airline: item.Flight.Airline, Number: item.Flight.PaddedNumber(), Direction: item.Direction}
+	if index[key] == nil {
+		index[key] = make(map[time.Time]scheduleEntry)
+	}
+	entry := entryOf(item)
+	// a turnaround departure with an overnight indicator is indexed on the dates it operates,
+	// so it is written back as a singular line that Parse accepts
+	series := item.Series()
+	if entry.DayChangeIndicator > 0 {
+		series = series.shift(entry.DayChangeIndicator)
+		entry.DayChangeIndicator = 0
+	}
+	for _, date := range series.Dates() {
+		index[key][date] = entry
+	}
+}
+
+func entryOf(item *SlotItem) scheduleEntry {
+	return scheduleEntry{
+		Flight:             item.Flight,
+		ScheduledTime:      item.ScheduledTime,
+		DayChangeIndicator: item.DayChangeIndicator,
+		AircraftType:       item.AircraftType,
+		Configuration:      item.Configuration,
+		ServiceType:        item.ServiceType,
+		Station:            item.Station,
+		AdjacentStation:    item.AdjacentStation,
+		ClearanceAirport:   item.ClearanceAirport,
+	}
+}
+
+// item builds a data line item for the entry operated on series
+func (e scheduleEntry) item(code ActionCode, direction Direction, series Series) *SlotItem {
+	return &SlotItem{
+		ActionCode:         code,
+		Flight:             e.Flight,
+		PeriodOfOperation:  series.Period,
+		DaysOfOperation:    series.Days,
+		FrequencyRate:      series.FrequencyRate,
+		AircraftType:       e.AircraftType,
+		Configuration:      e.Configuration,
+		ServiceType:        e.ServiceType,
+		Direction:          direction,
+		ClearanceAirport:   e.ClearanceAirport,
+		ScheduledTime:      e.ScheduledTime,
+		Station:            e.Station,
+		AdjacentStation:    e.AdjacentStation,
+		DayChangeIndicator: e.DayChangeIndicator,
+	}
+}
+
+// frequencyRates returns the frequency rate of every flight and direction whose slot lines all share the same rate
+func frequencyRates(items []*SlotItem) map[scheduleKey]int {
+	rates := make(map[scheduleKey]int)
+	mixed := make(map[scheduleKey]bool)
+	for _, item := range items {
+		if !holdsSlot(item.ActionCode) {
+			continue
+		}
+		key := scheduleKey{Airline: item.Flight.Airline, Number: item.Flight.PaddedNumber(), Direction: item.Direction}
+		if rate, ok := rates[key]; ok && rate != item.FrequencyRate {
+			mixed[key] = true
+		}
+		rates[key] = item.FrequencyRate
+	}
+	for key := range mixed {
+		delete(rates, key)
+	}
+	return rates
+}
+
+// holdsSlot reports whether a line with code describes an operated (held or requested) slot
+func holdsSlot(code ActionCode) bool {
+	switch code {
+	case ActionChangeSlot, ActionDeleteSlot, ActionEliminateSlot, ActionDeclineOffer,
+		ActionDeleteandAck, ActionUnableSlot, ActionUnableInfo:
+		return false
+	}
+	return true
+}
+
+// mergedKeys returns the keys of both indexes sorted by airline, flight number and direction
+func mergedKeys(a, b scheduleIndex) []scheduleKey {
+	keys := make([]scheduleKey, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Airline != keys[j].Airline {
+			return keys[i].Airline < keys[j].Airline
+		}
+		if keys[i].Number != keys[j].Number {
+			return keys[i].Number < keys[j].Number
+		}
+		return keys[i].Direction < keys[j].Direction
+	})
+	return keys
+}
+
+// diffMovement emits the lines for one flight and direction, ordered by the first date they affect.
+// Every date falls into at most one line so the order is stable. D lines and C/R pairs keep the
+// frequency rate of the previous lines, as they describe existing slots, N lines the one of the
+// planned lines, where it still fits the dates.
+func diffMovement(direction Direction, before, after map[time.Time]scheduleEntry, beforeRate, afterRate int) []*SlotItem {
+	type change struct{ old, next scheduleEntry }
+	deleted := make(map[scheduleEntry][]time.Time)
+	added := make(map[scheduleEntry][]time.Time)
+	changed := make(map[change][]time.Time)
+	for date, old := range before {
+		next, ok := after[date]
+		switch {
+		case !ok:
+			deleted[old] = append(deleted[old], date)
+		case next != old:
+			changed[change{old, next}] = append(changed[change{old, next}], date)
+		}
+	}
+	for date, next := range after {
+		if _, ok := before[date]; !ok {
+			added[next] = append(added[next], date)
+		}
+	}
+
+	type block struct {
+		start time.Time
+		items []*SlotItem
+	}
+	blocks := make([]block, 0)
+	emit := func(dates []time.Time, rate int, build func(Series) []*SlotItem) {
+		for _, series := range compactSeries(dates, rate) {
+			blocks = append(blocks, block{start: series.Period.Effective, items: build(series)})
+		}
+	}
+	for entry, dates := range deleted {
+		emit(dates, beforeRate, func(s Series) []*SlotItem { return []*SlotItem{entry.item(ActionDeleteSlot, direction, s)} })
+	}
+	for c, dates := range changed {
+		emit(dates, beforeRate, func(s Series) []*SlotItem {
+			return []*SlotItem{c.old.item(ActionChangeSlot, direction, s), c.next.item(ActionNewRevised, direction, s)}
+		})
+	}
+	for entry, dates := range added {
+		emit(dates, afterRate, func(s Series) []*SlotItem { return []*SlotItem{entry.item(ActionNewSlot, direction, s)} })
+	}
+
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].start.Before(blocks[j].start) })
+	items := make([]*SlotItem, 0, len(blocks))
+	for _, b := range blocks {
+		items = append(items, b.items...)
+	}
+	return items
+}
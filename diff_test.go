@@ -0,0 +1,86 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	const header = "SCR\nS25\n01MAR\nKRK\n"
+	tests := []struct {
+		name     string
+		previous string
+		planned  string
+		want     []string
+	}{
+		{
+			name:    "new fortnightly flight",
+			planned: "N LO014 07APR20OCT 1000000/2 189738 0930WAW J\n",
+			want:    []string{"N LO014 07APR20OCT 1000000/2 189738 0930WAW J"},
+		},
+		{
+			name:     "retime part of a series",
+			previous: "N LO012 30MAR25OCT 1234567 189738 0930WAW J\n",
+			planned:  "N LO012 30MAR31MAY 1234567 189738 0930WAW J\nN LO012 01JUN25OCT 1234567 189738 1000WAW J\n",
+			want: []string{
+				"C LO012 01JUN25OCT 1234567 189738 0930WAW J",
+				"R LO012 01JUN25OCT 1234567 189738 1000WAW J",
+			},
+		},
+		{
+			name:     "shortened fortnightly flight",
+			previous: "N LO014 07APR20OCT 1000000/2 189738 0930WAW J\n",
+			planned:  "N LO014 07APR08SEP 1000000/2 189738 0930WAW J\n",
+			want:     []string{"D LO014 22SEP20OCT 1000000/2 189738 0930WAW J"},
+		},
+		{
+			name:     "fortnightly flight retimed and made weekly",
+			previous: "N LO014 07APR20OCT 1000000/2 189738 0930WAW J\n",
+			planned:  "N LO014 07APR27OCT 1000000 189738 1000WAW J\n",
+			want: []string{
+				"C LO014 07APR20OCT 1000000/2 189738 0930WAW J",
+				"R LO014 07APR20OCT 1000000/2 189738 1000WAW J",
+				"N LO014 14APR27OCT 1000000/2 189738 1000WAW J",
+			},
+		},
+		{
+			name:     "dropped weekday",
+			previous: "N LO012 30MAR25OCT 1234567 189738 0930WAW J\n",
+			planned:  "N LO012 30MAR25OCT 1234560 189738 0930WAW J\n",
+			want:     []string{"D LO012 30MAR19OCT 0000007 189738 0930WAW J"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := Diff(parseSCR(t, header+tt.previous), parseSCR(t, header+tt.planned))
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			got := encodedDataLines(t, diff)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+	if _, err := Diff(nil, nil); err == nil {
+		t.Error("Diff(nil, nil) succeeded")
+	}
+}
+
+func TestDiffOvernightTurnaround(t *testing.T) {
+	const header = "SCR\nS25\n01MAR\nWAW\n"
+	previous := parseSCR(t, header+"NLO015 LO016 06APR26OCT 0000007 320321 JFK2310 00351JFK JJ\n")
+	planned := parseSCR(t, header+"NLO015 LO016 06APR26OCT 0000007 320321 JFK2310 00401JFK JJ\n")
+	diff, err := Diff(previous, planned)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	want := []string{
+		"C LO016 07APR27OCT 1000000 320321 0035JFK J",
+		"R LO016 07APR27OCT 1000000 320321 0040JFK J",
+	}
+	reparsed := parseSCR(t, encodeMessage(t, diff))
+	if got := encodedDataLines(t, reparsed); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
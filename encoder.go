@@ -0,0 +1,209 @@
+package ssimparser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encoder writes SCRMessage values as IATA SCR text.
+// The output follows the same layout the parser reads:
+//
+//	SCR
+//	/ABC123 (creator reference)
+//	S23
+//	01MAY
+//	ICN
+//	REYT/01MAY23/XYZ987 (reply reference)
+//	<administrative lines>
+//	<data lines>
+//	SI ...
+//	GI ...
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes msg to the underlying writer.
+// Turnaround halves stored next to each other in msg.Items are joined back into a single data line.
+func (e *Encoder) Encode(msg *SCRMessage) error {
+	if msg == nil {
+		return errors.New("ssimparser: cannot encode nil message")
+	}
+	bw := bufio.NewWriter(e.w)
+
+	identifier := msg.Identifier
+	if identifier == "" {
+		identifier = "SCR"
+	}
+	header := []string{identifier, referenceLine(msg.CreatorReference), msg.Season.String(), msg.MessageDate, msg.AirportCode, referenceLine(msg.ReplyReference)}
+	for _, line := range header {
+		if line == "" {
+			continue
+		}
+		writeLine(bw, line)
+	}
+	for _, line := range msg.AdministrativeLines {
+		writeLine(bw, line)
+	}
+
+	for i := 0; i < len(msg.Items); i++ {
+		item := msg.Items[i]
+		if item == nil {
+			return fmt.Errorf("ssimparser: cannot encode nil slot item #%d", i+1)
+		}
+		var (
+			line string
+			err  error
+		)
+		if item.Turnaround && i+1 < len(msg.Items) && msg.Items[i+1] != nil && msg.Items[i+1].Turnaround {
+			line, err = encodeTurnaroundLine(item, msg.Items[i+1])
+			i++
+		} else {
+			line, err = encodeSingularLine(item)
+		}
+		if err != nil {
+			return err
+		}
+		writeLine(bw, line)
+	}
+
+	writeInfoLines(bw, "SI", msg.SpecialInfo)
+	writeInfoLines(bw, "GI", msg.GeneralInfo)
+
+	return bw.Flush()
+}
+
+// MarshalText encodes the message as SCR text.
+// It makes SCRMessage an encoding.TextMarshaler.
+func (msg SCRMessage) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(&msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func referenceLine(ref *Reference) string {
+	if ref == nil {
+		return ""
+	}
+	return ref.String()
+}
+
+func writeLine(w *bufio.Writer, line string) {
+	w.WriteString(line)
+	w.WriteString("\n")
+}
+
+func writeInfoLines(w *bufio.Writer, prefix string, info string) {
+	if info == "" {
+		return
+	}
+	for _, line := range strings.Split(info, "\n") {
+		writeLine(w, strings.TrimSpace(prefix+" "+line))
+	}
+}
+
+// encodeScheduleFields writes the fields shared by every data line:
+// period of operation, days of operation and seats/aircraft type
+func encodeScheduleFields(s *SlotItem) (string, error) {
+	if s.PeriodOfOperation == nil {
+		return "", fmt.Errorf("ssimparser: slot item %s has no period of operation", s.Flight)
+	}
+	days := s.DaysOfOperation.String()
+	if s.FrequencyRate > 1 {
+		days += fmt.Sprintf("/%d", s.FrequencyRate)
+	}
+	return fmt.Sprintf("%s%s %s %s%s",
+		s.PeriodOfOperation.EffectiveDate,
+		s.PeriodOfOperation.TerminationDate,
+		days,
+		s.Configuration,
+		s.AircraftType,
+	), nil
+}
+
+// encodeTurnaroundLine joins the two halves of a turnaround back into one data line
+// e.g. NBA998 BA997 19OCT19OCT 1000000 168320 MAN1125 1215MAN CC
+// The line carries the supplementary elements of both halves.
+func encodeTurnaroundLine(a, b *SlotItem) (string, error) {
+	arrival, departure := a, b
+	if arrival.Direction != DirectionArrival {
+		arrival, departure = b, a
+	}
+	if arrival.Direction != DirectionArrival || departure.Direction != DirectionDeparture {
+		return "", fmt.Errorf("ssimparser: turnaround %s/%s needs one arrival and one departure", a.Flight, b.Flight)
+	}
+	schedule, err := encodeScheduleFields(arrival)
+	if err != nil {
+		return "", err
+	}
+	line := fmt.Sprintf("%s%s %s %s %s %s %s%s",
+		arrival.ActionCode,
+		arrival.Flight,
+		departure.Flight,
+		schedule,
+		encodeArrivalStation(arrival),
+		encodeDepartureStation(departure),
+		arrival.ServiceType, departure.ServiceType,
+	)
+	if supplementary := mergeSupplementary(arrival.Supplementary, departure.Supplementary); supplementary != nil && supplementary.String() != "" {
+		line += " " + supplementary.String()
+	}
+	return line, nil
+}
+
+// encodeSingularLine writes an arrival-only or departure-only data line.
+// Departure lines carry a space between the action code and the flight designator.
+func encodeSingularLine(s *SlotItem) (string, error) {
+	schedule, err := encodeScheduleFields(s)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(string(s.ActionCode))
+	switch s.Direction {
+	case DirectionArrival:
+		sb.WriteString(fmt.Sprintf("%s %s %s", s.Flight, schedule, encodeArrivalStation(s)))
+	case DirectionDeparture:
+		sb.WriteString(fmt.Sprintf(" %s %s %s", s.Flight, schedule, encodeDepartureStation(s)))
+	default:
+		return "", fmt.Errorf("ssimparser: slot item %s has neither arrival nor departure", s.Flight)
+	}
+	if s.ServiceType != "" {
+		sb.WriteString(" " + string(s.ServiceType))
+	}
+	if s.Supplementary != nil && s.Supplementary.String() != "" {
+		sb.WriteString(" " + s.Supplementary.String())
+	}
+	return sb.String(), nil
+}
+
+// encodeArrivalStation writes origin, previous station (when different) and arrival time e.g. JFKLHR0500
+func encodeArrivalStation(s *SlotItem) string {
+	if s.AdjacentStation != "" && s.AdjacentStation != s.Station {
+		return s.Station + s.AdjacentStation + s.ScheduledTime
+	}
+	return s.Station + s.ScheduledTime
+}
+
+// encodeDepartureStation writes departure time, overnight indicator (when set), next station (when different)
+// and destination e.g. 0030LHRJFK or 00301KIX
+func encodeDepartureStation(s *SlotItem) string {
+	departureTime := s.ScheduledTime
+	if s.DayChangeIndicator > 0 {
+		departureTime += fmt.Sprintf("%d", s.DayChangeIndicator)
+	}
+	if s.AdjacentStation != "" && s.AdjacentStation != s.Station {
+		return departureTime + s.AdjacentStation + s.Station
+	}
+	return departureTime + s.Station
+}
@@ -0,0 +1,61 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeFrequencyRateAndOvernight(t *testing.T) {
+	message := parseSCR(t, `SCR
+S25
+01MAR
+WAW
+N LO014 07APR20OCT 1000000 /2 189738 0930CDG J
+NLO015 LO016 06APR26OCT 0000007 320321 JFK2310 00351JFK JJ
+`)
+	encoded := encodeMessage(t, message)
+	for _, want := range []string{
+		"N LO014 07APR20OCT 1000000/2 189738 0930CDG J\n",
+		"NLO015 LO016 06APR26OCT 0000007 320321 JFK2310 00351JFK JJ\n",
+	} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded message is missing %q\n%s", want, encoded)
+		}
+	}
+}
+
+func TestEncodeTurnaroundSupplementary(t *testing.T) {
+	const message = "SCR\nS23\n01MAY\nICN\nNAB123 AB124 26MAR28OCT 1234567 189738 PVG0110 0210PVG JJ\n"
+	tests := []struct {
+		name               string
+		arrival, departure *SupplementaryData
+		want               string
+	}{
+		{"departure only", nil, &SupplementaryData{Remarks: []string{"LATE DEPARTURE"}}, "JJ / RE.LATE DEPARTURE/"},
+		{"arrival only", &SupplementaryData{PreviousStation: "NRT"}, nil, "JJ / PS.NRT/"},
+		{"empty on both halves", &SupplementaryData{}, &SupplementaryData{}, "0210PVG JJ"},
+		{
+			"both halves",
+			&SupplementaryData{CoordinatorReference: "ICN1", Remarks: []string{"A"}},
+			&SupplementaryData{NextStation: "HKG", Remarks: []string{"A"}},
+			"JJ / CR.ICN1/ NS.HKG/ RE.A/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseSCR(t, message)
+			parsed.Items[0].Supplementary, parsed.Items[1].Supplementary = tt.arrival, tt.departure
+			if line := strings.Split(encodeMessage(t, parsed), "\n")[4]; !strings.HasSuffix(line, tt.want) {
+				t.Errorf("line %q, want suffix %q", line, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeEmptySupplementary(t *testing.T) {
+	parsed := parseSCR(t, "SCR\nS23\n01MAY\nICN\nN AB456 26MAR28OCT 0204060 189738 0030KIX J\n")
+	parsed.Items[0].Supplementary = &SupplementaryData{}
+	if line := strings.Split(encodeMessage(t, parsed), "\n")[4]; line != "N AB456 26MAR28OCT 0204060 189738 0030KIX J" {
+		t.Errorf("line %q, want no trailing separator", line)
+	}
+}
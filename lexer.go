@@ -0,0 +1,509 @@
+package ssimparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Lexer splits an SCR or SAL message into classified lines.
+// Classification only looks at the grammar of a single line:
+//
+//	SCR, SAL                              identifier
+//	/ABC123, REYT/15OCT25/                creator / reply reference
+//	W25                                   season
+//	15OCT                                 message date
+//	KRK                                   airport
+//	X FR7840 01JAN01JAN 0004000 ...       data line
+//	SI ..., GI ...                        supplementary / general information
+//	END                                   end marker
+//
+// A line starting with a valid action code and a flight designator is a data line even when
+// its elements are malformed, so the parser reports it instead of dropping it.
+// Everything else is LineText. Deciding what a line means in its position
+// (e.g. a second airport-like line is administrative) is left to the parser.
+type Lexer struct {
+	scanner    *bufio.Scanner
+	lineNumber int
+}
+
+type LineKind int
+
+const (
+	LineText LineKind = iota
+	LineIdentifier
+	LineCreatorReference
+	LineSeason
+	LineDate
+	LineAirport
+	LineData
+	LineSupplementaryInfo
+	LineGeneralInfo
+	LineEnd
+)
+
+func (k LineKind) String() string {
+	switch k {
+	case LineText:
+		return "Text"
+	case LineIdentifier:
+		return "Identifier"
+	case LineCreatorReference:
+		return "CreatorReference"
+	case LineSeason:
+		return "Season"
+	case LineDate:
+		return "Date"
+	case LineAirport:
+		return "Airport"
+	case LineData:
+		return "Data"
+	case LineSupplementaryInfo:
+		return "SupplementaryInfo"
+	case LineGeneralInfo:
+		return "GeneralInfo"
+	case LineEnd:
+		return "End"
+	default:
+		return fmt.Sprintf("LineKind(%d)", k)
+	}
+}
+
+type TokenType int
+
+const (
+	TokenText TokenType = iota
+	TokenIdentifier
+	TokenReference
+	TokenSeason
+	TokenDate
+	TokenAirport
+	TokenInfoPrefix
+	TokenEnd
+	// Data line elements
+	TokenActionCode
+	TokenFlightDesignator
+	TokenPeriod
+	TokenDays
+	TokenFrequencyRate
+	TokenEquipment
+	TokenArrival
+	TokenDeparture
+	TokenServiceType
+	TokenSupplementary
+)
+
+func (t TokenType) String() string {
+	switch t {
+	case TokenText:
+		return "Text"
+	case TokenIdentifier:
+		return "Identifier"
+	case TokenReference:
+		return "Reference"
+	case TokenSeason:
+		return "Season"
+	case TokenDate:
+		return "Date"
+	case TokenAirport:
+		return "Airport"
+	case TokenInfoPrefix:
+		return "InfoPrefix"
+	case TokenEnd:
+		return "End"
+	case TokenActionCode:
+		return "ActionCode"
+	case TokenFlightDesignator:
+		return "FlightDesignator"
+	case TokenPeriod:
+		return "Period"
+	case TokenDays:
+		return "Days"
+	case TokenFrequencyRate:
+		return "FrequencyRate"
+	case TokenEquipment:
+		return "Equipment"
+	case TokenArrival:
+		return "Arrival"
+	case TokenDeparture:
+		return "Departure"
+	case TokenServiceType:
+		return "ServiceType"
+	case TokenSupplementary:
+		return "Supplementary"
+	default:
+		return fmt.Sprintf("TokenType(%d)", t)
+	}
+}
+
+// Token is a single element of a line
+type Token struct {
+	Type   TokenType
+	Value  string
+	Column int // 1-based column of the first character in the raw line
+}
+
+// Line is a classified message line
+type Line struct {
+	Number int    // 1-based line number in the message
+	Raw    string // line as read, without the line terminator
+	Kind   LineKind
+	Tokens []Token
+}
+
+// Text returns the line without surrounding whitespace
+func (l Line) Text() string {
+	return strings.TrimSpace(l.Raw)
+}
+
+// NewLexer returns a lexer reading lines from r
+func NewLexer(r io.Reader) *Lexer {
+	return &Lexer{scanner: bufio.NewScanner(r)}
+}
+
+// Next returns the next non-empty line, io.EOF when the input is exhausted
+func (l *Lexer) Next() (*Line, error) {
+	for l.scanner.Scan() {
+		l.lineNumber++
+		raw := l.scanner.Text()
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		return LexLine(l.lineNumber, raw), nil
+	}
+	if err := l.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// LineNumber returns the number of the last line read
+func (l *Lexer) LineNumber() int {
+	return l.lineNumber
+}
+
+// LexLine classifies a single raw line and splits it into tokens
+func LexLine(number int, raw string) *Line {
+	line := &Line{Number: number, Raw: raw}
+	fields := splitFields(raw)
+	text := strings.TrimSpace(raw)
+
+	switch {
+	case len(fields) == 0:
+		line.Kind = LineText
+	case text == "SI" || strings.HasPrefix(text, "SI "):
+		line.Kind = LineSupplementaryInfo
+		line.Tokens = infoTokens(fields, TokenInfoPrefix)
+	case text == "GI" || strings.HasPrefix(text, "GI "):
+		line.Kind = LineGeneralInfo
+		line.Tokens = infoTokens(fields, TokenInfoPrefix)
+	case text == "END":
+		line.Kind = LineEnd
+		line.Tokens = []Token{{Type: TokenEnd, Value: text, Column: fields[0].Column}}
+	case len(fields) == 1:
+		line.Kind, line.Tokens = lexSingleField(fields[0])
+	case isReferenceLine(text):
+		line.Kind = LineCreatorReference
+		line.Tokens = []Token{{Type: TokenReference, Value: text, Column: fields[0].Column}}
+	default:
+		if tokens, ok := lexDataLine(fields, raw); ok {
+			line.Kind = LineData
+			line.Tokens = tokens
+		} else if tokens, ok := lexMalformedDataLine(fields, raw); ok {
+			line.Kind = LineData
+			line.Tokens = tokens
+		} else {
+			line.Kind = LineText
+			line.Tokens = fields
+		}
+	}
+	return line
+}
+
+// lexSingleField classifies lines made of one field: identifier, reference, season, date or airport
+func lexSingleField(field Token) (LineKind, []Token) {
+	value := field.Value
+	switch {
+	case value == "SCR" || value == "SAL":
+		field.Type = TokenIdentifier
+		return LineIdentifier, []Token{field}
+	case isReferenceLine(value):
+		field.Type = TokenReference
+		return LineCreatorReference, []Token{field}
+	case isSeasonToken(value):
+		field.Type = TokenSeason
+		return LineSeason, []Token{field}
+	case len(value) == 5 && isDateDDMMM(value):
+		field.Type = TokenDate
+		return LineDate, []Token{field}
+	case isStationCode(value):
+		field.Type = TokenAirport
+		return LineAirport, []Token{field}
+	}
+	return LineText, []Token{field}
+}
+
+// lexDataLine recognises the schedule data line grammar
+//
+//	<action><designator> [<designator>] <period> <days>[/<rate>] <equipment> <arrival|departure>... [<service types>]
+//	<action> <designator> <period> <days> [/<rate>] <equipment> <departure> [<service type>]
+//
+// The frequency rate (e.g. /2 for fortnightly) may be attached to the days of operation or stand on its own.
+// Anything from the first "/" after the equipment to the end of the line is one supplementary token,
+// so free text remarks may contain spaces.
+// The period is the anchor of the grammar: it must follow the action code and one or two designators,
+// and be followed by days of operation (7 digits) and equipment (6 characters).
+// Element contents are validated later by the parser.
+func lexDataLine(fields []Token, raw string) ([]Token, bool) {
+	// work on a copy so a line that turns out to be text keeps its plain fields
+	fields = append([]Token(nil), fields...)
+	first := fields[0]
+	if !isUpperLetter(first.Value[0]) {
+		return nil, false
+	}
+	tokens := make([]Token, 0, len(fields)+1)
+	tokens = append(tokens, Token{Type: TokenActionCode, Value: first.Value[:1], Column: first.Column})
+	if len(first.Value) > 1 {
+		tokens = append(tokens, Token{Type: TokenFlightDesignator, Value: first.Value[1:], Column: first.Column + 1})
+	}
+
+	i := 1
+	for ; i < len(fields) && !isPeriodShape(fields[i].Value); i++ {
+		fields[i].Type = TokenFlightDesignator
+		tokens = append(tokens, fields[i])
+	}
+	designators := len(tokens) - 1
+	if i == len(fields) || designators < 1 || designators > 2 {
+		return nil, false
+	}
+
+	fields[i].Type = TokenPeriod
+	tokens = append(tokens, fields[i])
+	i++
+	if i == len(fields) || !isDaysShape(fields[i].Value) {
+		return nil, false
+	}
+	days := fields[i]
+	days.Type = TokenDays
+	if slash := strings.Index(days.Value, "/"); slash > 0 {
+		rate := Token{Type: TokenFrequencyRate, Value: days.Value[slash:], Column: days.Column + slash}
+		days.Value = days.Value[:slash]
+		tokens = append(tokens, days, rate)
+	} else {
+		tokens = append(tokens, days)
+	}
+	i++
+	if i < len(fields) && isFrequencyRateShape(fields[i].Value) {
+		fields[i].Type = TokenFrequencyRate
+		tokens = append(tokens, fields[i])
+		i++
+	}
+	if i == len(fields) || !isEquipmentShape(fields[i].Value) {
+		return nil, false
+	}
+	fields[i].Type = TokenEquipment
+	tokens = append(tokens, fields[i])
+	return append(tokens, lexMovementTokens(fields[i+1:], raw)...), true
+}
+
+// lexMalformedDataLine recognises a data line that fails the shape checks of lexDataLine,
+// e.g. a typo in the days of operation or a short equipment element.
+// It needs a valid action code followed by a valid flight designator or a period-shaped element.
+// The remaining fields are assigned by position so the parser can report the failing element
+// instead of the line silently becoming free text.
+func lexMalformedDataLine(fields []Token, raw string) ([]Token, bool) {
+	fields = append([]Token(nil), fields...)
+	first := fields[0]
+	if !ActionCode(first.Value[:1]).IsValid() {
+		return nil, false
+	}
+	tokens := make([]Token, 0, len(fields)+1)
+	tokens = append(tokens, Token{Type: TokenActionCode, Value: first.Value[:1], Column: first.Column})
+	recognised := false
+	if len(first.Value) > 1 {
+		_, err := ParseFlightDesignator(first.Value[1:])
+		recognised = err == nil
+		tokens = append(tokens, Token{Type: TokenFlightDesignator, Value: first.Value[1:], Column: first.Column + 1})
+	}
+	i := 1
+	for ; i < len(fields) && len(tokens) < 3; i++ {
+		if _, err := ParseFlightDesignator(fields[i].Value); err != nil {
+			break
+		}
+		recognised = true
+		fields[i].Type = TokenFlightDesignator
+		tokens = append(tokens, fields[i])
+	}
+	if !recognised && (i == len(fields) || !isPeriodShape(fields[i].Value)) {
+		return nil, false
+	}
+
+	if i < len(fields) {
+		fields[i].Type = TokenPeriod
+		tokens = append(tokens, fields[i])
+		i++
+	}
+	if i < len(fields) {
+		days := fields[i]
+		days.Type = TokenDays
+		if slash := strings.Index(days.Value, "/"); slash > 0 {
+			rate := Token{Type: TokenFrequencyRate, Value: days.Value[slash:], Column: days.Column + slash}
+			days.Value = days.Value[:slash]
+			tokens = append(tokens, days, rate)
+		} else {
+			tokens = append(tokens, days)
+		}
+		i++
+	}
+	if i < len(fields) && isFrequencyRateShape(fields[i].Value) {
+		fields[i].Type = TokenFrequencyRate
+		tokens = append(tokens, fields[i])
+		i++
+	}
+	if i < len(fields) {
+		fields[i].Type = TokenEquipment
+		tokens = append(tokens, fields[i])
+		i++
+	}
+	return append(tokens, lexMovementTokens(fields[i:], raw)...), true
+}
+
+// lexMovementTokens classifies the elements following the equipment:
+// arrival, departure, service types and the trailing supplementary token
+func lexMovementTokens(fields []Token, raw string) []Token {
+	tokens := make([]Token, 0, len(fields))
+	for i := range fields {
+		value := fields[i].Value
+		if value[0] == '/' {
+			column := fields[i].Column
+			tokens = append(tokens, Token{Type: TokenSupplementary, Value: strings.TrimSpace(raw[column-1:]), Column: column})
+			break
+		}
+		switch {
+		case isDigit(value[0]):
+			fields[i].Type = TokenDeparture
+		case isServiceTypeShape(value):
+			fields[i].Type = TokenServiceType
+		case isUpperLetter(value[0]):
+			fields[i].Type = TokenArrival
+		default:
+			fields[i].Type = TokenText
+		}
+		tokens = append(tokens, fields[i])
+	}
+	return tokens
+}
+
+// infoTokens splits SI/GI lines into the prefix and the free text following it
+func infoTokens(fields []Token, prefix TokenType) []Token {
+	tokens := []Token{{Type: prefix, Value: fields[0].Value, Column: fields[0].Column}}
+	if len(fields) > 1 {
+		tokens = append(tokens, fields[1:]...)
+	}
+	return tokens
+}
+
+// splitFields splits raw on whitespace and records the column of every field
+func splitFields(raw string) []Token {
+	fields := make([]Token, 0)
+	start := -1
+	for i := 0; i <= len(raw); i++ {
+		if i == len(raw) || raw[i] == ' ' || raw[i] == '\t' || raw[i] == '\r' {
+			if start >= 0 {
+				fields = append(fields, Token{Type: TokenText, Value: raw[start:i], Column: start + 1})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return fields
+}
+
+// isReferenceLine matches creator and reply reference lines e.g. /ABC123 or REYT/15OCT25/
+func isReferenceLine(s string) bool {
+	if strings.HasPrefix(s, "/") && len(s) > 1 {
+		return true
+	}
+	slash := strings.Index(s, "/")
+	if slash < 2 || slash > 4 {
+		return false
+	}
+	for i := 0; i < slash; i++ {
+		if !isUpperLetter(s[i]) {
+			return false
+		}
+	}
+	return !strings.Contains(s[:slash], " ")
+}
+
+func isSeasonToken(s string) bool {
+	_, err := ParseSeason(s)
+	return err == nil
+}
+
+// isPeriodShape checks the DDMMMDDMMM layout without validating the dates
+func isPeriodShape(s string) bool {
+	if len(s) != 10 {
+		return false
+	}
+	for i := 0; i < 10; i++ {
+		isDatePart := i%5 < 2
+		if isDatePart && !isDigit(s[i]) || !isDatePart && !isUpperLetter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isFrequencyRateShape matches a frequency rate element e.g. /2
+func isFrequencyRateShape(s string) bool {
+	return len(s) == 2 && s[0] == '/' && isDigit(s[1])
+}
+
+// isDaysShape matches days of operation e.g. 1234567 or 1030500, optionally followed by a frequency rate
+func isDaysShape(s string) bool {
+	days, _, _ := strings.Cut(s, "/")
+	if len(days) != 7 {
+		return false
+	}
+	for i := 0; i < 7; i++ {
+		if !isDigit(days[i]) && days[i] != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// isEquipmentShape matches 3 digit seats and an aircraft type e.g. 189738 or 18973H
+func isEquipmentShape(s string) bool {
+	if len(s) != 6 {
+		return false
+	}
+	for i := 0; i < 6; i++ {
+		if !isDigit(s[i]) && (i < 3 || !isUpperLetter(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// isServiceTypeShape matches one (single movement) or two (turnaround) service type letters
+func isServiceTypeShape(s string) bool {
+	if len(s) > 2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isUpperLetter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpperLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,54 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLexerLineKinds(t *testing.T) {
+	tests := []struct {
+		line string
+		want LineKind
+	}{
+		{"SCR", LineIdentifier},
+		{"SAL", LineIdentifier},
+		{"/ABC123", LineCreatorReference},
+		{"REYT/15OCT25/ABC123", LineCreatorReference},
+		{"W25", LineSeason},
+		{"15OCT", LineDate},
+		{"KRK", LineAirport},
+		{"N AB456 26MAR28OCT 0204060 189738 0030KIX J", LineData},
+		{"NAB123 AB124 26MAR28OCT 1234567 189738 PVG0110 0210PVG JJ", LineData},
+		{"NAB123 AB124 26MAR28OCT 1234567 189738 PVG2310 00101PVG JJ", LineData},
+		{"N LO014 07APR20OCT 1000000/2 189738 0930WAW J", LineData},
+		{"N LO014 07APR20OCT 1000000 /2 189738 0930WAW J", LineData},
+		{"KAB457 26MAR28OCT 0204060 189738 KIX0500 J / RE.SUBJECT TO APRON CAPACITY/", LineData},
+		{"PLEASE CONFIRM 01JAN01JAN THANKS", LineText},
+		{"PLEASE CONFIRM 01JAN01JAN 1234567 THANKS", LineText},
+		{"N AB456 26MAR28OCT", LineData},
+		{"N LO10 01JAN31JAN 12x4567 18973H GOT1105 J", LineData},
+		{"N LO10 01JA31JAN 1234567 18973H GOT1105 J", LineData},
+		{"N 01JAN31JAN 1234567 973H GOT1105 J", LineData},
+		{"SI ALL TIMES IN UTC", LineSupplementaryInfo},
+		{"GI BRGDS", LineGeneralInfo},
+		{"END", LineEnd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			line, err := NewLexer(strings.NewReader(tt.line + "\n")).Next()
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if line.Kind != tt.want {
+				t.Errorf("kind = %v, want %v", line.Kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeTextDoesNotEndHeader(t *testing.T) {
+	message := parseSCR(t, "SCR\nW25\n15OCT\nKRK\nPLEASE CONFIRM 01JAN01JAN THANKS\nN AB456 26OCT28MAR 0204060 189738 0030KIX J\n")
+	if len(message.AdministrativeLines) != 1 || len(message.Items) != 1 {
+		t.Errorf("administrative %q items %d, want the text line as administrative and 1 item", message.AdministrativeLines, len(message.Items))
+	}
+}
@@ -3,6 +3,7 @@ package ssimparser
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // SCR
@@ -18,14 +19,22 @@ import (
 
 type SCRMessage struct {
 	Identifier  string // "SCR"
-	Season      string // S23 - S for Summer W for Winter
+	Season      Season // S23 - S for Summer W for Winter
 	MessageDate string // DDMMM format
 	AirportCode string // IATA 3-letter code e.g KRK
-	// Administrative lines
+	// Header references, nil when absent
+	CreatorReference *Reference // /ABC123
+	ReplyReference   *Reference // REYT/15OCT25/ABC123
+	// Originator is the sending side, detected from the action codes (see DetectOriginator)
+	Originator Originator
+	// Administrative lines that are not decoded, kept verbatim
 	AdministrativeLines []string
 	//Payload core: slice of the individual slot requests/replies
 	Items []*SlotItem
+	// Changes links C/R and X/K lines of Items, see SlotChange
+	Changes []*SlotChange
 	// Optional addtional information GI - General Information, SI - Supplementary Information
+	// Multiple GI/SI lines are joined with a newline
 	GeneralInfo string
 	SpecialInfo string
 }
@@ -38,6 +47,13 @@ func (msg SCRMessage) PrettyPrint() string {
 	sb.WriteString(fmt.Sprintf("Season:       %s\n", msg.Season))
 	sb.WriteString(fmt.Sprintf("Message Date: %s\n", msg.MessageDate))
 	sb.WriteString(fmt.Sprintf("Airport Code: %s\n", msg.AirportCode))
+	sb.WriteString(fmt.Sprintf("Originator:   %s\n", msg.Originator))
+	if msg.CreatorReference != nil {
+		sb.WriteString(fmt.Sprintf("Creator Ref:  %s\n", msg.CreatorReference.ID))
+	}
+	if msg.ReplyReference != nil {
+		sb.WriteString(fmt.Sprintf("Reply To:     %s\n", msg.ReplyReference))
+	}
 	sb.WriteString("-----------------------------------\n")
 
 	if len(msg.AdministrativeLines) > 0 {
@@ -58,13 +74,17 @@ func (msg SCRMessage) PrettyPrint() string {
 
 	if msg.GeneralInfo != "" {
 		sb.WriteString("General Information (GI):\n")
-		sb.WriteString(fmt.Sprintf("  %s\n", msg.GeneralInfo))
+		for _, line := range strings.Split(msg.GeneralInfo, "\n") {
+			sb.WriteString(fmt.Sprintf("  %s\n", line))
+		}
 		sb.WriteString("-----------------------------------\n")
 	}
 
 	if msg.SpecialInfo != "" {
 		sb.WriteString("Special Information (SI):\n")
-		sb.WriteString(fmt.Sprintf("  %s\n", msg.SpecialInfo))
+		for _, line := range strings.Split(msg.SpecialInfo, "\n") {
+			sb.WriteString(fmt.Sprintf("  %s\n", line))
+		}
 		sb.WriteString("-----------------------------------\n")
 	}
 
@@ -75,9 +95,12 @@ func (s SlotItem) prettyPrint(index int) string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("  #%d\n", index))
 	sb.WriteString(fmt.Sprintf("    Action Code:        %s\n", s.ActionCode))
-	sb.WriteString(fmt.Sprintf("    Carrier / Flight:   %s %s\n", s.CarrierCode, s.FlightNumber))
+	sb.WriteString(fmt.Sprintf("    Carrier / Flight:   %s %s%s\n", s.Flight.Airline, s.Flight.Number, s.Flight.Suffix))
 	sb.WriteString(fmt.Sprintf("%v\n", s.PeriodOfOperation.prettyPrint()))
 	sb.WriteString(fmt.Sprintf("    Days of Operation:  %s\n", s.DaysOfOperation))
+	if s.FrequencyRate > 1 {
+		sb.WriteString(fmt.Sprintf("    Frequency Rate:     every %d weeks\n", s.FrequencyRate))
+	}
 	if s.AircraftType != "" {
 		sb.WriteString(fmt.Sprintf("    Aircraft Type:      %s\n", s.AircraftType))
 	}
@@ -88,13 +111,22 @@ func (s SlotItem) prettyPrint(index int) string {
 		sb.WriteString(fmt.Sprintf("    Service Type:       %s\n", s.ServiceType))
 	}
 
-	if s.DepartureAirport != "" {
-		sb.WriteString(fmt.Sprintf("    Departure:          %s at %s UTC\n",
-			s.DepartureAirport, s.DepartureTimeUTC))
+	switch s.Direction {
+	case DirectionArrival:
+		sb.WriteString(fmt.Sprintf("    Arrival:            %s at %s UTC from %s (ΔDay %+d)\n",
+			s.ClearanceAirport, s.ScheduledTime, s.Station, s.DayChangeIndicator))
+	case DirectionDeparture:
+		sb.WriteString(fmt.Sprintf("    Departure:          %s at %s UTC to %s (ΔDay %+d)\n",
+			s.ClearanceAirport, s.ScheduledTime, s.Station, s.DayChangeIndicator))
+	}
+	if s.AdjacentStation != "" && s.AdjacentStation != s.Station {
+		sb.WriteString(fmt.Sprintf("    Via:                %s\n", s.AdjacentStation))
 	}
-	if s.ArrivalAirport != "" {
-		sb.WriteString(fmt.Sprintf("    Arrival:            %s at %s UTC (ΔDay %+d)\n",
-			s.ArrivalAirport, s.ArrivalTimeUTC, s.DayChangeIndicator))
+
+	if s.Supplementary != nil {
+		for _, element := range s.Supplementary.Elements() {
+			sb.WriteString(fmt.Sprintf("    Supplementary:      %s\n", element))
+		}
 	}
 
 	if s.SlotKey != "" {
@@ -112,50 +144,106 @@ type SlotItem struct {
 	ActionCode ActionCode
 
 	// Slot Identfication Data
-	CarrierCode  string
-	FlightNumber string
+	Flight FlightDesignator
 
 	// ScheduleData
 	PeriodOfOperation *PeriodOfOperation //Period FIXME: change to Period
-	DaysOfOperation   string             //[]int FIXME: change to []int
-	AircraftType      string             // IATA 3-letter CODE
-	Configuration     string             // Capacity/Seats
+	DaysOfOperation   DaysOfWeek
+	FrequencyRate     int    // operates every n-th week, 0 (or 1) for every week
+	AircraftType      string // IATA 3-letter CODE
+	Configuration     string // Capacity/Seats
 
 	ServiceType ServiceType
 
-	// Arrival data - if exists
-	ArrivalAirport     string
-	ArrivalTimeUTC     string
+	// Movement at the coordinated airport
+	Direction        Direction
+	ClearanceAirport string // coordinated airport from the message header
+	ScheduledTime    string // HHMM UTC at the coordinated airport
+
+	// Station is the origin for arrivals and the destination for departures.
+	// AdjacentStation is the previous station for arrivals and the next station for departures,
+	// equal to Station when the flight has no intermediate stop.
+	Station         string
+	AdjacentStation string
+
+	// Overnight indicator: days between the arrival of the turnaround (the day the period
+	// and days of operation refer to) and this departure
 	DayChangeIndicator int
 
-	//Departure data - if exists
-	DepartureAirport string
-	DepartureTimeUTC string
+	// Trailing "/ ..." elements of the data line, nil when the line has none
+	Supplementary *SupplementaryData
+
+	// Turnaround marks the item as one half of a turnaround line.
+	// Both halves are kept next to each other in SCRMessage.Items.
+	Turnaround bool
 
 	//Internal Metadata
 	RawDataLine string
 	LineNumber  int
 
-	// SLOT KEY
+	// SLOT KEY - see GetSlotKey, filled in by the parser
 	SlotKey string
 }
 
-func (s SlotItem) GetSlotKey(clearanceAirport string) string {
-	return fmt.Sprintf("%s-%s-%s-%s-%s",
-		s.CarrierCode,
-		s.FlightNumber,
-		//FIXME: fix this later
-		"a", "b",
-		//s.PeriodOfOperation.EffectiveDate,
-		//s.PeriodOfOperation.TerminationDate,
-		clearanceAirport,
+// GetSlotKey builds the stable identity of the slot series:
+//
+//	<carrier>-<flight number><suffix>-<direction>-<effective>-<termination>-<clearance airport>
+//	e.g. FR-7840A-D-20260101-20260131-KRK
+//
+// The flight number is zero padded to four digits so LO10 and LO010 give the same key,
+// the direction is A or D and both period dates are the resolved YYYYMMDD calendar dates.
+// A request, the coordinator reply and a stored row for the same series share the key.
+func (s SlotItem) GetSlotKey() string {
+	effective, termination := "00000000", "00000000"
+	if s.PeriodOfOperation != nil {
+		effective = s.PeriodOfOperation.Effective.Format(slotKeyDateLayout)
+		termination = s.PeriodOfOperation.Termination.Format(slotKeyDateLayout)
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s-%s",
+		s.Flight.Airline,
+		s.Flight.PaddedNumber(),
+		s.Direction,
+		effective,
+		termination,
+		s.ClearanceAirport,
 	)
 }
 
+// NormalisePeriod tightens the period of s so it starts and ends on an operating day,
+// as expected by coordinators. It reports whether the period was changed.
+// A series without any operating day is left as it is.
+func (s *SlotItem) NormalisePeriod() bool {
+	dates := s.Series().Dates()
+	if len(dates) == 0 {
+		return false
+	}
+	first, last := dates[0], dates[len(dates)-1]
+	if first.Equal(s.PeriodOfOperation.Effective) && last.Equal(s.PeriodOfOperation.Termination) {
+		return false
+	}
+	s.PeriodOfOperation = NewPeriodOfOperation(first, last)
+	s.SlotKey = s.GetSlotKey()
+	return true
+}
+
+// NormalisePeriods applies SlotItem.NormalisePeriod to every item and returns how many were changed
+func (msg *SCRMessage) NormalisePeriods() int {
+	changed := 0
+	for _, item := range msg.Items {
+		if item.NormalisePeriod() {
+			changed++
+		}
+	}
+	return changed
+}
+
+const slotKeyDateLayout = "20060102"
+
 type ActionCode string
 
 const (
 	// Airline Action Codes
+	// P is used by both sides, see ActionCodeInfo for the meaning per originator
 	ActionNewRequest         ActionCode = "A" // Acceptance of an offer - no further improvement desired
 	ActionNewEntrant         ActionCode = "B" // New Entrant
 	ActionChangeSlot         ActionCode = "C" // Slot to be changed
@@ -182,6 +270,14 @@ const (
 
 )
 
+// Direction of the movement at the coordinated airport
+type Direction string
+
+const (
+	DirectionArrival   Direction = "A"
+	DirectionDeparture Direction = "D"
+)
+
 type ServiceType string
 
 const (
@@ -197,13 +293,36 @@ const (
 )
 
 type PeriodOfOperation struct {
-	EffectiveDate   string //
-	TerminationDate string
+	EffectiveDate   string // DDMMM as written in the message
+	TerminationDate string // DDMMM as written in the message
 	DurationDays    int
+	// Calendar dates resolved against the message season
+	Effective   time.Time
+	Termination time.Time
 }
 
+// POOFromString parses a DDMMMDDMMM period without a season.
+// Dates are resolved against a leap reference year, use POOFromStringInSeason for real calendar dates.
 func POOFromString(s string) (*PeriodOfOperation, error) {
-	return poocreator(s)
+	return poocreator(s, Season{})
+}
+
+// POOFromStringInSeason parses a DDMMMDDMMM period and resolves it against season e.g. W25.
+// Winter periods like 26OCT28MAR end in the year following the season year.
+func POOFromStringInSeason(s string, season Season) (*PeriodOfOperation, error) {
+	return poocreator(s, season)
+}
+
+// NewPeriodOfOperation builds a period from calendar dates, the DDMMM fields are filled from them
+func NewPeriodOfOperation(from, to time.Time) *PeriodOfOperation {
+	from, to = truncateToDate(from), truncateToDate(to)
+	return &PeriodOfOperation{
+		EffectiveDate:   formatDDMMM(from),
+		TerminationDate: formatDDMMM(to),
+		DurationDays:    DaysBetween(to, from),
+		Effective:       from,
+		Termination:     to,
+	}
 }
 
 func (poo PeriodOfOperation) prettyPrint() string {
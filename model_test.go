@@ -0,0 +1,97 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetSlotKeyDeterministic(t *testing.T) {
+	request := `SCR
+W25
+15OCT
+KRK
+NLO10 01JAN31JAN 1234567 18973H GOT1105 J
+N LO11 01JAN31JAN 1234567 18973H 1155GOT J
+`
+	reply := `SCR
+W25
+16OCT
+KRK
+KLO010 01JAN31JAN 1234567 18973H GOT1105 J
+K LO011 01JAN31JAN 1234567 18973H 1155GOT J
+`
+	parse := func(message string) []*SlotItem {
+		t.Helper()
+		msg, err := NewScrParser().Parse(strings.NewReader(message))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		return msg.Items
+	}
+	first, second, replied := parse(request), parse(request), parse(reply)
+
+	want := []string{
+		"LO-0010-A-20260101-20260131-KRK",
+		"LO-0011-D-20260101-20260131-KRK",
+	}
+	for i, item := range first {
+		if item.SlotKey != want[i] {
+			t.Errorf("item %d: SlotKey = %q, want %q", i, item.SlotKey, want[i])
+		}
+		if item.GetSlotKey() != item.SlotKey {
+			t.Errorf("item %d: GetSlotKey() = %q differs from SlotKey %q", i, item.GetSlotKey(), item.SlotKey)
+		}
+		if second[i].SlotKey != item.SlotKey {
+			t.Errorf("item %d: key changed between parses: %q, %q", i, item.SlotKey, second[i].SlotKey)
+		}
+		if replied[i].SlotKey != item.SlotKey {
+			t.Errorf("item %d: reply key %q, want the request key %q", i, replied[i].SlotKey, item.SlotKey)
+		}
+	}
+}
+
+func TestGetSlotKeyDistinguishesSeries(t *testing.T) {
+	base := SlotItem{Flight: FlightDesignator{Airline: "FR", Number: "7840"}, Direction: DirectionDeparture, ClearanceAirport: "KRK"}
+	arrival := base
+	arrival.Direction = DirectionArrival
+	suffixed := base
+	suffixed.Flight.Suffix = "A"
+	elsewhere := base
+	elsewhere.ClearanceAirport = "WAW"
+
+	seen := map[string]bool{base.GetSlotKey(): true}
+	for _, item := range []SlotItem{arrival, suffixed, elsewhere} {
+		key := item.GetSlotKey()
+		if seen[key] {
+			t.Errorf("key %q is shared with another series", key)
+		}
+		seen[key] = true
+	}
+	if got := suffixed.GetSlotKey(); got != "FR-7840A-D-00000000-00000000-KRK" {
+		t.Errorf("GetSlotKey() = %q", got)
+	}
+}
+
+func TestNormalisePeriod(t *testing.T) {
+	message := "SCR\nW25\n15OCT\nKRK\nN LO11 01JAN31JAN 1000000 18973H 1155GOT J\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n"
+	parser := NewScrParser()
+	parser.SetNormalisePeriods(true)
+	parsed, err := parser.Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := parsed.Items[0].PeriodOfOperation; got.EffectiveDate != "05JAN" || got.TerminationDate != "26JAN" {
+		t.Errorf("period = %s%s, want 05JAN26JAN", got.EffectiveDate, got.TerminationDate)
+	}
+	if got := parsed.Items[0].SlotKey; got != "LO-0011-D-20260105-20260126-KRK" {
+		t.Errorf("SlotKey = %q, not updated", got)
+	}
+	if parsed.Items[1].NormalisePeriod() {
+		t.Error("single operating day period was changed")
+	}
+
+	empty := SlotItem{PeriodOfOperation: parsed.Items[1].PeriodOfOperation, DaysOfOperation: Monday}
+	if empty.NormalisePeriod() {
+		t.Error("period without an operating day was changed")
+	}
+}
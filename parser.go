@@ -1,9 +1,8 @@
 package ssimparser
 
 import (
-	"bufio"
+	"fmt"
 	"io"
-	"strings"
 )
 
 // SCR
@@ -20,21 +19,31 @@ import (
 // SCR \n W25 \n 15OCT \n KRK
 // Then any number of administrative lines
 
+// SCRParser is implemented by ScrParser.
+// Parse failures are returned as *ParserError wrapping one of the Err* sentinels.
 type SCRParser interface {
 	Parse(r io.Reader) (*SCRMessage, error)
 }
 
+var _ SCRParser = (*ScrParser)(nil)
+
 type ScrParser struct {
+	// Deprecated: data lines are recognised by their grammar (see Lexer), the minimum length is ignored.
 	MIN_SSIM_LINE_LENGTH int
 	validator            *ParsingValidator // Optional validator for collecting issues
+	lenient              bool              // Record line failures and continue instead of aborting
+	originator           Originator        // Expected sender, codes of the other side are rejected
+	normalise            bool              // Tighten periods to the first and last operating day
 }
 
 // Non-Argument Initializer
 func NewScrParser() *ScrParser {
-	return &ScrParser{MIN_SSIM_LINE_LENGTH: 37}
+	return &ScrParser{}
 }
 
-// Argument Initializer
+// NewScrParserWithMinLength creates a parser that records minLength in MIN_SSIM_LINE_LENGTH.
+//
+// Deprecated: the minimum line length is ignored, use NewScrParser.
 func NewScrParserWithMinLength(minLength int) *ScrParser {
 	return &ScrParser{MIN_SSIM_LINE_LENGTH: minLength}
 }
@@ -53,12 +62,13 @@ func NewScrParserWithMinLength(minLength int) *ScrParser {
 //	report := validator.Report() // get validation report
 func NewScrParserWithValidator() *ScrParser {
 	return &ScrParser{
-		MIN_SSIM_LINE_LENGTH: 37,
-		validator:            NewParsingValidator(),
+		validator: NewParsingValidator(),
 	}
 }
 
-// NewScrParserWithValidatorAndMinLength creates a parser with validator and custom min length
+// NewScrParserWithValidatorAndMinLength creates a parser with validator that records minLength in MIN_SSIM_LINE_LENGTH.
+//
+// Deprecated: the minimum line length is ignored, use NewScrParserWithValidator.
 func NewScrParserWithValidatorAndMinLength(minLength int) *ScrParser {
 	return &ScrParser{
 		MIN_SSIM_LINE_LENGTH: minLength,
@@ -66,6 +76,51 @@ func NewScrParserWithValidatorAndMinLength(minLength int) *ScrParser {
 	}
 }
 
+// NewLenientScrParser creates a parser in lenient mode with an embedded validator.
+// A data line that cannot be parsed is recorded with the severity of the failing step and skipped,
+// so a single typo does not hide the problems on the remaining lines. When only one half of a
+// turnaround line can be read, that half is kept and the issue is recorded as Major.
+//
+// Usage:
+//
+//	parser := NewLenientScrParser()
+//	message, err := parser.Parse(reader) // err only for unrecoverable failures
+//	report := parser.GetValidator().Report()
+func NewLenientScrParser() *ScrParser {
+	return &ScrParser{
+		validator: NewParsingValidator(),
+		lenient:   true,
+	}
+}
+
+// SetLenient switches lenient mode on or off.
+// In lenient mode a validator is created on demand to collect the line failures.
+func (scr *ScrParser) SetLenient(lenient bool) {
+	scr.lenient = lenient
+}
+
+// IsLenient returns true if the parser records line failures instead of aborting
+func (scr *ScrParser) IsLenient() bool {
+	return scr.lenient
+}
+
+// SetOriginator restricts the accepted action codes to the ones the given side may send.
+// OriginatorUnknown (the default) accepts every valid code.
+func (scr *ScrParser) SetOriginator(o Originator) {
+	scr.originator = o
+}
+
+// GetOriginator returns the expected message originator
+func (scr *ScrParser) GetOriginator() Originator {
+	return scr.originator
+}
+
+// SetNormalisePeriods makes Parse tighten every period to its first and last operating day,
+// see SlotItem.NormalisePeriod
+func (scr *ScrParser) SetNormalisePeriods(normalise bool) {
+	scr.normalise = normalise
+}
+
 // SetValidator attaches a validator to the parser.
 // This allows you to use a shared validator across multiple parsers,
 // or attach a validator to an existing parser.
@@ -95,114 +150,183 @@ func (scr *ScrParser) addValidationIssue(message string, lineNumber int, rawLine
 	}
 }
 
-func (scr *ScrParser) Parse(r io.Reader) (*SCRMessage, *ParserError) {
+// Parse reads a complete SCR message.
+// The returned error is a *ParserError, use errors.As to get the line details
+// and errors.Is with the Err* sentinels to find out the kind of failure.
+// In lenient mode line failures are added to the validator instead and the
+// message is returned with every line that could be parsed.
+// A message with another identifier (e.g. a SAL, see ParseSAL) fails with ErrUnexpectedMessage.
+func (scr *ScrParser) Parse(r io.Reader) (*SCRMessage, error) {
+	message, err := scr.parseMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	if message.Identifier != "" && message.Identifier != "SCR" {
+		return nil, NewParserError("expected SCR identifier", 0, message.Identifier, ErrUnexpectedMessage, Critical)
+	}
+	return message, nil
+}
+
+// parseMessage reads the header and data lines shared by SCR and SAL messages
+func (scr *ScrParser) parseMessage(r io.Reader) (*SCRMessage, error) {
+	if scr.lenient {
+		scr.GetValidator()
+	}
 	message := &SCRMessage{
 		AdministrativeLines: make([]string, 0),
 		Items:               make([]*SlotItem, 0),
 	}
 	headerComplete := false
-	lineNumber := 0
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
-		// if empty just skip
-		if len(line) == 0 {
-			continue
+	lexer := NewLexer(r)
+	for {
+		line, err := lexer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewParserError("reading message failed", lexer.LineNumber(), "", err, Critical)
+		}
+		if line.Kind == LineEnd {
+			break
+		}
+		// The header ends with the first data, SI or GI line
+		switch line.Kind {
+		case LineData, LineSupplementaryInfo, LineGeneralInfo:
+			headerComplete = true
 		}
 		if !headerComplete {
-			if scr.isSlotDataLine(line) || strings.HasPrefix(line, "GI") || strings.HasPrefix(line, "SI") {
-				headerComplete = true
-			} else {
-				if err := scr.parseHeader(line, message, lineNumber); err != nil {
-					return nil, err
-				}
-				continue
+			if perr := scr.parseHeader(line, message); perr != nil {
+				return nil, perr
 			}
+			continue
 		}
-		if headerComplete {
-			switch {
-			case scr.isSlotDataLine(line):
-				items, err := scr.parseData(line, lineNumber, message.AirportCode)
-				if err != nil {
-					return nil, err
+		text := line.Text()
+		switch line.Kind {
+		case LineData:
+			items, perr := scr.parseData(line, message.AirportCode, message.Season)
+			if perr != nil {
+				if !scr.lenient {
+					return nil, perr
 				}
-				for _, item := range items {
-					item.GetSlotKey(message.AirportCode)
-					message.Items = append(message.Items, item)
+				// The line is skipped or partly kept, the message itself can still be used
+				scr.validator.AddError(perr)
+			}
+			for _, item := range items {
+				if item.ServiceType == "" {
+					scr.addValidationIssue("missing service type", line.Number, text, nil, Minor)
 				}
-
-			case strings.HasPrefix(line, "GI"):
-				message.GeneralInfo += line[2:]
-			case strings.HasPrefix(line, "SI"):
-				message.SpecialInfo += line[2:]
-			default:
+				item.SlotKey = item.GetSlotKey()
+				message.Items = append(message.Items, item)
 			}
+
+		case LineGeneralInfo:
+			message.GeneralInfo = appendInfoLine(message.GeneralInfo, text[2:])
+		case LineSupplementaryInfo:
+			message.SpecialInfo = appendInfoLine(message.SpecialInfo, text[2:])
+		default:
+			scr.addValidationIssue(fmt.Sprintf("unexpected %v line after the header", line.Kind), line.Number, text, nil, Minor)
 		}
 	}
+	if scr.normalise {
+		message.NormalisePeriods()
+	}
+	message.Changes = LinkChanges(message.Items)
+	message.Originator = scr.originator
+	if message.Originator == OriginatorUnknown {
+		message.Originator = DetectOriginator(message)
+	}
 	return message, nil
 }
 
-func (scr *ScrParser) parseHeader(line string, message *SCRMessage, lineNumber int) *ParserError {
-	tokens := strings.Fields(line)
+// parseHeader fills the header field matching the line kind.
+// Anything that does not fit (or repeats an already known field) is kept as administrative line.
+func (scr *ScrParser) parseHeader(line *Line, message *SCRMessage) *ParserError {
+	text := line.Text()
 
-	if line == "SCR" {
-		message.Identifier = "SCR"
-		return nil
-	}
-	//Process single-field lines
-	if len(tokens) == 1 {
-		if message.Season == "" && len(tokens[0]) == 3 && (tokens[0][0] == 'S' || tokens[0][0] == 'W') {
-			message.Season = tokens[0]
+	switch line.Kind {
+	case LineIdentifier:
+		if message.Identifier == "" {
+			message.Identifier = text
 			return nil
 		}
-		if message.MessageDate == "" && len(tokens[0]) == 5 && isDateDDMMM(tokens[0]) {
-			message.MessageDate = tokens[0]
+		// SAL is also the airport code of San Salvador
+		if message.AirportCode == "" && isStationCode(text) {
+			message.AirportCode = text
+			return nil
+		}
+	case LineSeason:
+		if message.Season.IsZero() {
+			season, err := ParseSeason(text)
+			if err == nil {
+				message.Season = season
+				return nil
+			}
+		}
+	case LineDate:
+		if message.MessageDate == "" {
+			message.MessageDate = text
 			return nil
 		}
-		if message.AirportCode == "" && len(tokens[0]) == 3 {
-			message.AirportCode = tokens[0]
+	case LineAirport:
+		if message.AirportCode == "" {
+			message.AirportCode = text
+			return nil
+		}
+	case LineCreatorReference:
+		ref, err := ParseReference(text)
+		if err != nil {
+			break
+		}
+		if ref.Type == ReferenceCreator && message.CreatorReference == nil {
+			message.CreatorReference = ref
+			return nil
+		}
+		if ref.Type == ReferenceReply && message.ReplyReference == nil {
+			message.ReplyReference = ref
 			return nil
 		}
 	}
 	// Process rest as administrative
-	message.AdministrativeLines = append(message.AdministrativeLines, line)
+	message.AdministrativeLines = append(message.AdministrativeLines, text)
 
 	return nil
 }
-func (scr *ScrParser) parseData(line string, lineNumber int, messageAirportCode string) ([]*SlotItem, *ParserError) {
-	tokens := strings.Fields(line)
+func (scr *ScrParser) parseData(line *Line, messageAirportCode string, season Season) ([]*SlotItem, *ParserError) {
+	text := line.Text()
 	bucket := make([]*SlotItem, 0, 2)
 
 	// Three cases: Turnaround - 2 SlotItems
 	// Arrival or departure - 1 SlotItem
 
 	// separate functions to deal with it
-	if len(tokens) == 8 {
-		turnarounds, err := parseTurnaroundLine(tokens, line, lineNumber)
+	d, err := newDataLine(line.Tokens)
+	if err != nil {
+		return nil, NewParserError("malformed data line", line.Number, text, err, Critical)
+	}
+	if !d.actionCode.IsValid() {
+		err := fmt.Errorf("%w: %v", ErrUnknownActionCode, d.actionCode)
+		return nil, NewParserError("unknown action code", line.Number, text, err, Critical)
+	}
+	if !d.actionCode.AllowedFor(scr.originator) {
+		err := fmt.Errorf("%w: %v in %v message", ErrActionNotAllowed, d.actionCode, scr.originator)
+		return nil, NewParserError("action code not allowed", line.Number, text, err, Critical)
+	}
+	if d.isTurnaround() {
+		turnarounds, err := parseTurnaroundLine(d, text, line.Number, messageAirportCode, season)
+		if err != nil && len(turnarounds) > 0 {
+			// one half is still usable
+			return turnarounds, NewParserError("turnaround line partly parsed", line.Number, text, err, Major)
+		}
 		if err != nil {
-			return nil, NewParserError("ssimparser: parsing turnaround parser error", lineNumber, line, err, Critical)
+			return nil, NewParserError("turnaround line parser error", line.Number, text, err, Critical)
 		}
 		bucket = append(bucket, turnarounds...)
 	} else {
-		slot, err := parseSingularLine(tokens, line, lineNumber)
+		slot, err := parseSingularLine(d, text, line.Number, messageAirportCode, season)
 		if err != nil {
-			return nil, NewParserError("ssimparser: single slot parser error", lineNumber, line, err, Critical)
+			return nil, NewParserError("single slot parser error", line.Number, text, err, Critical)
 		}
 		bucket = append(bucket, slot)
 	}
 	return bucket, nil
 }
-
-// ssimparser/parser.go or ssimparser/parser_util.go
-
-// isSlotDataLine checks if a line starts with a recognized Action Code,
-// indicating it is a Schedule Information Data Line (SlotItem data).
-func (p *ScrParser) isSlotDataLine(line string) bool {
-	if len(line) >= p.MIN_SSIM_LINE_LENGTH {
-		return true
-	}
-	return false
-	//TODO: Add better check later
-}
@@ -1,6 +1,27 @@
 package ssimparser
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors describing why a data line could not be parsed.
+// They are wrapped by ParserError so callers can branch with errors.Is:
+//
+//	if errors.Is(err, ssimparser.ErrBadPeriod) { ... }
+var (
+	ErrBadPeriod           = errors.New("ssimparser: bad period of operation")
+	ErrBadDaysOfOperation  = errors.New("ssimparser: bad days of operation")
+	ErrBadFlightDesignator = errors.New("ssimparser: bad flight designator")
+	ErrBadStation          = errors.New("ssimparser: bad station")
+	ErrUnknownActionCode   = errors.New("ssimparser: unknown action code")
+	ErrActionNotAllowed    = errors.New("ssimparser: action code not allowed for message originator")
+	ErrMalformedLine       = errors.New("ssimparser: malformed data line")
+	ErrBadRecord           = errors.New("ssimparser: bad SSIM record")
+	ErrBadScheduleMessage  = errors.New("ssimparser: bad schedule message")
+	ErrUnexpectedMessage   = errors.New("ssimparser: unexpected message identifier")
+)
 
 type SCRErrorLevel int
 
@@ -32,7 +53,19 @@ type ParserError struct {
 }
 
 func (e ParserError) Error() string {
-	return fmt.Sprintf("ssimparser: %s at line %d: %s", e.Message, e.LineNumber, e.RawLine)
+	if e.Err != nil {
+		// the cause carries its own package prefix, keep only the leading one
+		cause := strings.TrimPrefix(e.Err.Error(), errorPrefix)
+		return fmt.Sprintf("%s%s at line %d: %s: %s", errorPrefix, e.Message, e.LineNumber, e.RawLine, cause)
+	}
+	return fmt.Sprintf("%s%s at line %d: %s", errorPrefix, e.Message, e.LineNumber, e.RawLine)
+}
+
+const errorPrefix = "ssimparser: "
+
+// Unwrap returns the underlying error so errors.Is and errors.As can inspect the cause
+func (e ParserError) Unwrap() error {
+	return e.Err
 }
 
 func NewParserError(message string, lineNumber int, rawLine string, err error, severity SCRErrorLevel) *ParserError {
@@ -61,15 +94,57 @@ func (pv *ParsingValidator) ValidateSCR(message *SCRMessage) {
 	if message.Identifier != "SCR" {
 		pv.AddError(NewParserError("missing SCR identifier", 0, "", nil, Critical))
 	}
-	if message.Season == "" {
+	pv.validateMessage(message)
+}
+
+// validateMessage runs the checks shared by SCR and SAL messages
+func (pv *ParsingValidator) validateMessage(message *SCRMessage) {
+	if message.Season.IsZero() {
 		pv.AddError(NewParserError("missing season", 0, "", nil, Critical))
 	}
 	if message.AirportCode == "" {
 		pv.AddError(NewParserError("missing airport code", 0, "", nil, Critical))
 	}
+	// A message is sent by one side, airline and coordinator codes cannot be mixed
+	airline, coordinator := originatorCodes(message.Items)
+	if len(airline) > 0 && len(coordinator) > 0 {
+		msg := fmt.Sprintf("message mixes airline %v and coordinator %v action codes", airline, coordinator)
+		pv.AddError(NewParserError(msg, 0, "", nil, Major))
+	} else if message.Originator != OriginatorUnknown {
+		for _, item := range message.Items {
+			if !item.ActionCode.AllowedFor(message.Originator) {
+				msg := fmt.Sprintf("action code %v not allowed in %v message", item.ActionCode, message.Originator)
+				pv.AddError(NewParserError(msg, item.LineNumber, item.RawDataLine, nil, Major))
+			}
+		}
+	}
+	pv.validateChanges(message.Items)
+	pv.validatePeriods(message.Items)
 	// Add more validation rules as needed
 }
 
+// validatePeriods flags periods that do not start or end on an operating day (Minor)
+// and periods without any operating day (Major)
+func (pv *ParsingValidator) validatePeriods(items []*SlotItem) {
+	for _, item := range items {
+		if item.PeriodOfOperation == nil {
+			continue
+		}
+		period := item.PeriodOfOperation
+		dates := item.Series().Dates()
+		if len(dates) == 0 {
+			msg := fmt.Sprintf("period %s%s has no operating day on %s", period.EffectiveDate, period.TerminationDate, item.DaysOfOperation)
+			pv.AddError(NewParserError(msg, item.LineNumber, item.RawDataLine, nil, Major))
+			continue
+		}
+		if !dates[0].Equal(period.Effective) || !dates[len(dates)-1].Equal(period.Termination) {
+			msg := fmt.Sprintf("period %s%s does not start and end on operating days, expected %s%s",
+				period.EffectiveDate, period.TerminationDate, formatDDMMM(dates[0]), formatDDMMM(dates[len(dates)-1]))
+			pv.AddError(NewParserError(msg, item.LineNumber, item.RawDataLine, nil, Minor))
+		}
+	}
+}
+
 func (pv *ParsingValidator) AssesErrors() (int, int, int) {
 	minor, major, critical := 0, 0, 0
 	for _, el := range pv.Container {
@@ -89,7 +164,7 @@ func (pv *ParsingValidator) Report() string {
 	if critical > 0 {
 		return fmt.Sprintf("There is %v minor, %v major and %v CRITICAL errors, therefore it is impossible to create SCR", minor, major, critical)
 	}
-	if critical == 0 && major > 1 {
+	if critical == 0 && major > 0 {
 		return fmt.Sprintf("There is %v minor and %v major errors, therefore fixes need to be introduced to create SCR", minor, major)
 	}
 	return fmt.Sprintf("There is %v minor errors - SCR will be created but consider fixing those issues", minor)
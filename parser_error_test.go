@@ -0,0 +1,84 @@
+package ssimparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		sentinel error
+	}{
+		{"period", "NLO10 01JAN32JAN 1234567 18973H GOT1105 J", ErrBadPeriod},
+		{"days", "NLO10 01JAN31JAN 12x4567 18973H GOT1105 J", ErrBadDaysOfOperation},
+		{"days order", "NLO10 01JAN31JAN 2134567 18973H GOT1105 J", ErrBadDaysOfOperation},
+		{"short period", "N LO10 01JA31JAN 1234567 18973H GOT1105 J", ErrBadPeriod},
+		{"short equipment", "N LO10 01JAN31JAN 1234567 973H GOT1105 J", ErrMalformedLine},
+		{"designator", "N12345 01JAN31JAN 1234567 18973H GOT1105 J", ErrBadFlightDesignator},
+		{"station", "NLO10 01JAN31JAN 1234567 18973H G1T1105 J", ErrBadStation},
+		{"action code", "QLO10 01JAN31JAN 1234567 18973H GOT1105 J", ErrUnknownActionCode},
+		{"malformed", "HLO10 LO11 01JAN31JAN 1234567 18973H GOT1105 1155GOT JJJ", ErrMalformedLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := "SCR\nW25\n15OCT\nKRK\n" + tt.line + "\n"
+			_, err := NewScrParser().Parse(strings.NewReader(message))
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("Parse error = %v, want %v", err, tt.sentinel)
+			}
+			var perr *ParserError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse error %T is not a *ParserError", err)
+			}
+			if perr.LineNumber != 5 || perr.RawLine != tt.line || perr.Severity != Critical {
+				t.Errorf("ParserError = line %d %q %v, want line 5 %q Critical", perr.LineNumber, perr.RawLine, perr.Severity, tt.line)
+			}
+			if n := strings.Count(err.Error(), "ssimparser:"); n != 1 {
+				t.Errorf("error has %d package prefixes: %v", n, err)
+			}
+		})
+	}
+}
+
+func TestReportSingleMajorIssue(t *testing.T) {
+	validator := NewParsingValidator()
+	validator.AddError(NewParserError("turnaround line partly parsed", 5, "", nil, Major))
+	if got := validator.Report(); !strings.Contains(got, "1 major") {
+		t.Errorf("Report() = %q, want the major issue to block the SCR", got)
+	}
+}
+
+func TestValidatePeriods(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		severity []SCRErrorLevel
+	}{
+		// 01JAN26 is a Thursday
+		{"single operating day", "SCR\nW25\n15OCT\nKRK\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n", nil},
+		{"endpoints off the operating days", "SCR\nW25\n15OCT\nKRK\nN LO11 01JAN31JAN 1000000 18973H 1155GOT J\n", []SCRErrorLevel{Minor}},
+		// 01JAN25 is a Wednesday
+		{"no operating day", "SCR\nW24\n15OCT\nKRK\nK FR7840 01JAN01JAN 0004000 18973H 1155GOT J\n", []SCRErrorLevel{Major}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := NewScrParser().Parse(strings.NewReader(tt.message))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			validator := NewParsingValidator()
+			validator.validatePeriods(parsed.Items)
+			if len(validator.Container) != len(tt.severity) {
+				t.Fatalf("issues = %v, want %d", validator.Container, len(tt.severity))
+			}
+			for i, severity := range tt.severity {
+				if got := validator.Container[i].Severity; got != severity {
+					t.Errorf("issue %d severity = %v, want %v", i, got, severity)
+				}
+			}
+		})
+	}
+}
@@ -1 +1,254 @@
 package ssimparser
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// corpus holds the example messages of the package documentation
+var corpus = []struct {
+	name    string
+	message string
+}{
+	{
+		name: "reply with change pairs",
+		message: `SCR
+W25
+15OCT
+KRK
+REYT/15OCT25/
+X FR7840 01JAN01JAN 0004000 18973H 1105GOT J
+K FR7840 01JAN01JAN 0004000 18973H 1155GOT J
+X FR5610 01JAN01JAN 0004000 18973H 1255FMM J
+K FR5610 01JAN01JAN 0004000 18973H 1315FMM J
+`,
+	},
+	{
+		name: "turnarounds with GI and SI",
+		message: `SCR
+S20
+22APR
+GVA
+NBA998 BA997 19OCT19OCT 1000000 168320 MAN1125 1215MAN CC
+NBA996 BA995 20OCT20OCT 0200000 259763 LGW1525 1615MAN CC
+NBA990 BA991 21OCT21OCT 0030000 168320 EDI0800 0850GLA CP
+GI BRGDS
+SI HAPPYEASTERACKACK
+`,
+	},
+	{
+		name: "initial request",
+		message: `SCR
+S23
+01MAY
+ICN
+NAB123 AB124 26MAR28OCT 1234567 189738 PVG0110 0210PVG JJ
+N AB456 26MAR28OCT 0204060 189738 0030KIX J
+NAB457 26MAR28OCT 0204060 189738 KIX0500 J
+SI ALL TIMES IN UTC
+SI IF UNAVBL PLS OFFR NEXT LATER AVBL
+GI BRGDS COMPANY/SENDER NAME
+`,
+	},
+	{
+		name: "frequency rate and overnight turnaround",
+		message: `SCR
+S25
+01MAR
+WAW
+N LO014 07APR20OCT 1000000/2 189738 0930CDG J
+NLO015 LO016 06APR26OCT 0000007 320321 JFK2310 00351JFK JJ
+`,
+	},
+	{
+		name: "references and supplementary data",
+		message: `SCR
+/ABC123
+S23
+01MAY
+ICN
+REYT/01MAY23/XYZ987
+KAB457 26MAR28OCT 0204060 189738 KIX0500 J / CR.KRK000123/ RE.SUBJECT TO APRON CAPACITY/
+KAB123 AB124 26MAR28OCT 1234567 189738 PVG0110 0210PVG JJ / CR.ICN000042/ RE.TURNAROUND/
+`,
+	},
+	{
+		name: "arrival and departure with administrative line",
+		message: `SCR
+W24
+20SEP
+WAW
+/ABC123
+NLO010 24OCT24OCT 0000500 252788 ORD0730 J
+N LO011 24OCT24OCT 0000500 252788 0930ORD J
+`,
+	},
+}
+
+func parseSCR(t *testing.T, message string) *SCRMessage {
+	t.Helper()
+	parsed, err := NewScrParser().Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return parsed
+}
+
+func parseSchedule(t *testing.T, message string) *ScheduleMessage {
+	t.Helper()
+	parsed, err := ParseScheduleMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseScheduleMessage: %v", err)
+	}
+	return parsed
+}
+
+func encodeMessage(t *testing.T, message *SCRMessage) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := NewEncoder(&sb).Encode(message); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return sb.String()
+}
+
+// encodedDataLines encodes message and returns the lines after the four header lines
+func encodedDataLines(t *testing.T, message *SCRMessage) []string {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(encodeMessage(t, message)), "\n")
+	return lines[4:]
+}
+
+// comparableItems drops the source position of every item
+func comparableItems(items []*SlotItem) []SlotItem {
+	result := make([]SlotItem, 0, len(items))
+	for _, item := range items {
+		copied := *item
+		copied.LineNumber, copied.RawDataLine = 0, ""
+		result = append(result, copied)
+	}
+	return result
+}
+
+func TestParseEncodeRoundTrip(t *testing.T) {
+	for _, tt := range corpus {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseSCR(t, tt.message)
+			if len(parsed.Items) == 0 {
+				t.Fatal("Parse returned no items")
+			}
+			encoded := encodeMessage(t, parsed)
+			reparsed, err := NewScrParser().Parse(strings.NewReader(encoded))
+			if err != nil {
+				t.Fatalf("Parse(Encode(msg)): %v\n%s", err, encoded)
+			}
+
+			if !reflect.DeepEqual(comparableItems(parsed.Items), comparableItems(reparsed.Items)) {
+				t.Errorf("items differ after the round trip\n%s", encoded)
+			}
+			if parsed.Identifier != reparsed.Identifier || parsed.Season != reparsed.Season ||
+				parsed.MessageDate != reparsed.MessageDate || parsed.AirportCode != reparsed.AirportCode {
+				t.Errorf("header differs after the round trip\n%s", encoded)
+			}
+			if !reflect.DeepEqual(parsed.CreatorReference, reparsed.CreatorReference) ||
+				!reflect.DeepEqual(parsed.ReplyReference, reparsed.ReplyReference) {
+				t.Errorf("references differ after the round trip\n%s", encoded)
+			}
+			if !reflect.DeepEqual(parsed.AdministrativeLines, reparsed.AdministrativeLines) {
+				t.Errorf("administrative lines differ after the round trip\n%s", encoded)
+			}
+			if parsed.GeneralInfo != reparsed.GeneralInfo || parsed.SpecialInfo != reparsed.SpecialInfo {
+				t.Errorf("GI/SI differ after the round trip\n%s", encoded)
+			}
+			if again := encodeMessage(t, reparsed); again != encoded {
+				t.Errorf("encoding is not stable\nfirst:\n%s\nsecond:\n%s", encoded, again)
+			}
+		})
+	}
+}
+
+func TestLenientParse(t *testing.T) {
+	message := `SCR
+W25
+15OCT
+KRK
+NLO10 01JAN31JAN 1234567 18973H GOT1105 J
+NLO12 01JAN32JAN 1234567 18973H GOT1205 J
+NLO14 LO15 01JAN31JAN 1234567 18973H GOT1305 14X5GOT JJ
+QLO16 01JAN31JAN 1234567 18973H GOT1405 J
+N LO17 01JAN31JAN 1234567 18973H 1505GOT
+N LO19 01JAN31JAN 1234567 18973H 1605GOT J
+N LO21 01JAN31JAN 12x4567 18973H 1705GOT J
+`
+	parser := NewLenientScrParser()
+	parsed, err := parser.Parse(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var flights []string
+	for _, item := range parsed.Items {
+		flights = append(flights, item.Flight.Number)
+	}
+	if want := []string{"10", "14", "17", "19"}; !reflect.DeepEqual(flights, want) {
+		t.Errorf("surviving flights = %v, want %v", flights, want)
+	}
+	if parsed.Items[1].Turnaround || parsed.Items[1].Direction != DirectionArrival {
+		t.Errorf("kept half of the turnaround = %+v, want a plain arrival", parsed.Items[1])
+	}
+
+	type issue struct {
+		line     int
+		severity SCRErrorLevel
+		sentinel error
+	}
+	want := []issue{
+		{6, Critical, ErrBadPeriod},
+		{7, Major, ErrBadStation},
+		{8, Critical, ErrUnknownActionCode},
+		{9, Minor, nil},
+		{11, Critical, ErrBadDaysOfOperation},
+	}
+	issues := parser.GetValidator().Container
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for i, w := range want {
+		got := issues[i]
+		if got.LineNumber != w.line || got.Severity != w.severity {
+			t.Errorf("issue %d: line %d %v, want line %d %v", i, got.LineNumber, got.Severity, w.line, w.severity)
+		}
+		if w.sentinel != nil && !errors.Is(got, w.sentinel) {
+			t.Errorf("issue %d: %v, want %v", i, got, w.sentinel)
+		}
+	}
+}
+
+func TestStrictParseStopsAtPartlyParsedTurnaround(t *testing.T) {
+	message := "SCR\nW25\n15OCT\nKRK\nHLO14 LO15 01JAN31JAN 1234567 18973H GOT1305 14X5GOT JJ\n"
+	parsed, err := NewScrParser().Parse(strings.NewReader(message))
+	if !errors.Is(err, ErrBadStation) || parsed != nil {
+		t.Errorf("Parse = %v, %v; want nil and %v", parsed, err, ErrBadStation)
+	}
+}
+
+func TestDeprecatedMinLengthParsers(t *testing.T) {
+	message := corpus[0].message
+	want := parseSCR(t, message)
+	for name, parser := range map[string]*ScrParser{
+		"NewScrParserWithMinLength":             NewScrParserWithMinLength(80),
+		"NewScrParserWithValidatorAndMinLength": NewScrParserWithValidatorAndMinLength(80),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := parser.Parse(strings.NewReader(message))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(comparableItems(got.Items), comparableItems(want.Items)) {
+				t.Errorf("items differ from NewScrParser, the minimum length must be ignored")
+			}
+		})
+	}
+}
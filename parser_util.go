@@ -11,6 +11,9 @@ import (
 
 // Return if string is format of SSIM Date DDMMM e.g. 05OCT, 15MAY, 21JUN
 func isDateDDMMM(date string) bool {
+	if len(date) != 5 {
+		return false
+	}
 	months := []string{
 		"JAN",
 		"FEB",
@@ -43,151 +46,307 @@ func isDateDDMMM(date string) bool {
 	return true
 }
 
-func isSlotDataLine(line string) bool {
-	actionCodes := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "L", "N", "P", "R", "V", "Y", "Z", "H", "K", "O", "P", "T", "U", "W", "X"}
-	code := string(line[0])
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// dataLine holds the elements of a lexed schedule data line
+type dataLine struct {
+	actionCode    ActionCode
+	designators   []string // arriving and/or departing flight designator
+	period        string
+	days          string
+	frequency     string // frequency rate element e.g. /2, empty for weekly
+	equipment     string // seats and aircraft type e.g. 189738
+	arrival       string
+	departure     string
+	serviceTypes  string
+	supplementary string // trailing "/ ..." elements
+}
 
-	if !slices.Contains(actionCodes, code) {
-		return false
+// newDataLine collects the lexer tokens of a data line and checks that every mandatory element is present
+func newDataLine(tokens []Token) (*dataLine, error) {
+	d := &dataLine{designators: make([]string, 0, 2)}
+	for _, tok := range tokens {
+		switch tok.Type {
+		case TokenActionCode:
+			d.actionCode = ActionCode(tok.Value)
+		case TokenFlightDesignator:
+			d.designators = append(d.designators, tok.Value)
+		case TokenPeriod:
+			d.period = tok.Value
+		case TokenDays:
+			d.days = tok.Value
+		case TokenFrequencyRate:
+			d.frequency = tok.Value
+		case TokenEquipment:
+			d.equipment = tok.Value
+		case TokenArrival:
+			if d.arrival != "" {
+				return nil, fmt.Errorf("%w: more than one arrival element at column %d", ErrMalformedLine, tok.Column)
+			}
+			d.arrival = tok.Value
+		case TokenDeparture:
+			if d.departure != "" {
+				return nil, fmt.Errorf("%w: more than one departure element at column %d", ErrMalformedLine, tok.Column)
+			}
+			d.departure = tok.Value
+		case TokenServiceType:
+			d.serviceTypes = tok.Value
+		case TokenSupplementary:
+			d.supplementary = tok.Value
+		default:
+			return nil, fmt.Errorf("%w: unexpected element %v at column %d", ErrMalformedLine, tok.Value, tok.Column)
+		}
 	}
-	tokens := strings.Fields(line)
-	if len(tokens) != 6 && len(tokens) != 7 && len(tokens) != 8 {
-		return false
+	if d.days == "" || d.equipment == "" {
+		return nil, fmt.Errorf("%w: missing days of operation or seats/aircraft type", ErrMalformedLine)
 	}
-	return true
+	if len(d.equipment) != 6 {
+		return nil, fmt.Errorf("%w: expected seats and aircraft type as 6 characters but have %v", ErrMalformedLine, d.equipment)
+	}
+	if d.arrival == "" && d.departure == "" {
+		return nil, fmt.Errorf("%w: missing arrival or departure element", ErrMalformedLine)
+	}
+	return d, nil
+}
+
+// isTurnaround reports whether the line describes both an arrival and a departure
+func (d *dataLine) isTurnaround() bool {
+	return d.arrival != "" && d.departure != ""
 }
 
 // Turnaround flight parser returns multiple slot info structs
-// FIXME: redudant tokens an line - tokens are build from line
 
-func parseTurnaroundLine(tokens []string, line string, lineNumber int) ([]*SlotItem, error) {
+func parseTurnaroundLine(d *dataLine, line string, lineNumber int, clearanceAirport string, season Season) ([]*SlotItem, error) {
 	//HLH4123 LH4876 01JUL26JUL 0034507 120319 HAM0700 0750FRA JJ
+	// arriving flight LH4123 from HAM at 0700, departing flight LH4876 to FRA at 0750
 
-	departure, arrival := &SlotItem{LineNumber: lineNumber, RawDataLine: line}, &SlotItem{LineNumber: lineNumber, RawDataLine: line}
-
-	// Shared Data fields
-	sharedActionCode := ActionCode(tokens[0][0:1])
-	rng := tokens[2]
-	doop := tokens[3]
-	cfg, aircraft := getConfAndAicraft(tokens[4])
+	arrival, departure := &SlotItem{LineNumber: lineNumber, RawDataLine: line, Turnaround: true}, &SlotItem{LineNumber: lineNumber, RawDataLine: line, Turnaround: true}
 
-	departure.ActionCode = sharedActionCode
-	var err error
-	departure.PeriodOfOperation, err = POOFromString(rng)
-	if err != nil {
-		return nil, fmt.Errorf("ssimparser: period of operation error: %v", err)
+	if len(d.designators) != 2 {
+		return nil, fmt.Errorf("%w: turnaround needs arriving and departing flight designators but have %d", ErrMalformedLine, len(d.designators))
 	}
-
-	departure.DaysOfOperation = doop
-	departure.Configuration = cfg
-	departure.AircraftType = aircraft
-	departure.RawDataLine = line
-	departure.LineNumber = lineNumber
-
-	arrival.ActionCode = sharedActionCode
-	arrival.PeriodOfOperation, err = POOFromString(rng)
-	if err != nil {
-		return nil, fmt.Errorf("ssimparser: period of operation error: %v", err)
+	if len(d.serviceTypes) != 2 {
+		return nil, fmt.Errorf("%w: turnaround needs two service types but have %v", ErrMalformedLine, d.serviceTypes)
 	}
-	arrival.DaysOfOperation = doop
-	arrival.AircraftType = aircraft
-	arrival.Configuration = cfg
-	arrival.RawDataLine = line
-	arrival.LineNumber = lineNumber
-
-	// Individual data fields
-	departureAirport := tokens[5][:3]
-	departureTime := tokens[5][3:]
-	carrier, fno, err := getFlightDetail(tokens[1])
+	// Shared Data fields
+	doop, err := ParseDaysOfWeek(d.days)
 	if err != nil {
-		return nil, errors.New("ssimparser: flight detail parse error")
+		return nil, err
 	}
-	departure.CarrierCode = carrier
-	departure.FlightNumber = fno
-
-	arrivalAirport := tokens[6][4:]
-	arrivalTime := tokens[6][:4]
-	carrier, fno, err = getFlightDetail(tokens[0][1:])
+	rate, err := parseFrequencyRate(d.frequency)
 	if err != nil {
-		return nil, errors.New("ssimparser: flight detail parse error")
+		return nil, err
 	}
-	arrival.CarrierCode = carrier
-	arrival.FlightNumber = fno
+	cfg, aircraft := getConfAndAicraft(d.equipment)
 
-	departure.DepartureAirport = departureAirport
-	departure.DepartureTimeUTC = departureTime
+	for _, item := range []*SlotItem{arrival, departure} {
+		item.ActionCode = d.actionCode
+		item.PeriodOfOperation, err = POOFromStringInSeason(d.period, season)
+		if err != nil {
+			return nil, err
+		}
+		item.DaysOfOperation = doop
+		item.FrequencyRate = rate
+		item.Configuration = cfg
+		item.AircraftType = aircraft
+		item.ClearanceAirport = clearanceAirport
+		item.Supplementary = ParseSupplementaryData(d.supplementary)
+	}
 
-	arrival.ArrivalAirport = arrivalAirport
-	arrival.ArrivalTimeUTC = arrivalTime
+	// Individual data fields
+	arrivalErr := fillTurnaroundHalf(arrival, DirectionArrival, d.designators[0], d.arrival, d.serviceTypes[0:1])
+	departureErr := fillTurnaroundHalf(departure, DirectionDeparture, d.designators[1], d.departure, d.serviceTypes[1:2])
 
-	departure.ServiceType = ServiceType(string(tokens[7][0]))
-	arrival.ServiceType = ServiceType(string(tokens[7][1]))
+	// A half that could not be read is dropped, the other one is returned as a plain
+	// arrival or departure together with the error so a lenient parser can keep it.
+	switch {
+	case arrivalErr != nil && departureErr != nil:
+		return nil, arrivalErr
+	case arrivalErr != nil:
+		departure.Turnaround = false
+		return []*SlotItem{departure}, arrivalErr
+	case departureErr != nil:
+		arrival.Turnaround = false
+		return []*SlotItem{arrival}, departureErr
+	}
 
-	bucket := make([]*SlotItem, 0)
-	bucket = append(bucket, departure)
+	bucket := make([]*SlotItem, 0, 2)
 	bucket = append(bucket, arrival)
+	bucket = append(bucket, departure)
 
 	return bucket, nil
 }
-func parseSingularLine(tokens []string, line string, lineNumber int) (*SlotItem, error) {
-	flight := &SlotItem{LineNumber: lineNumber, RawDataLine: line}
-	isDeparture := false
-	if len(tokens[0]) == 1 {
-		isDeparture = true
-	}
-	if isDeparture {
-		flight.ActionCode = ActionCode(tokens[0])
-		tokens = tokens[1:]
+
+// fillTurnaroundHalf sets the flight, station and service type of one half of a turnaround line
+func fillTurnaroundHalf(item *SlotItem, direction Direction, designator string, station string, serviceType string) error {
+	var err error
+	item.Flight, err = ParseFlightDesignator(designator)
+	if err != nil {
+		return err
+	}
+	item.Direction = direction
+	if direction == DirectionArrival {
+		item.Station, item.AdjacentStation, item.ScheduledTime, err = parseArrivalStation(station)
 	} else {
-		flight.ActionCode = ActionCode(tokens[0][0:1])
-		tokens[0] = tokens[0][1:]
+		item.ScheduledTime, item.DayChangeIndicator, item.AdjacentStation, item.Station, err = parseDepartureStation(station, true)
+	}
+	if err != nil {
+		return err
+	}
+	item.ServiceType = ServiceType(serviceType)
+	return nil
+}
+func parseSingularLine(d *dataLine, line string, lineNumber int, clearanceAirport string, season Season) (*SlotItem, error) {
+	//KLO010 24OCT24OCT 0000500 252788 ORD0730 J
+	//K LO010 24OCT24OCT 0000500 252788 0730ORD J
+	flight := &SlotItem{LineNumber: lineNumber, RawDataLine: line, ClearanceAirport: clearanceAirport, ActionCode: d.actionCode}
+	if len(d.designators) != 1 {
+		return nil, fmt.Errorf("%w: expected one flight designator but have %d", ErrMalformedLine, len(d.designators))
 	}
-	//->>>K<<<--LO010 24OCT24OCT 0000500 252788 ORD0730 J
-	//LO010 24OCT24OCT 0000500 252788 0730ORD J
-	carrier, fno, err := getFlightDetail(tokens[0])
+	if len(d.serviceTypes) > 1 {
+		return nil, fmt.Errorf("%w: expected one service type but have %v", ErrMalformedLine, d.serviceTypes)
+	}
+	var err error
+	flight.Flight, err = ParseFlightDesignator(d.designators[0])
 	if err != nil {
-		return nil, errors.New("ssimparser: flight detail parser error")
+		return nil, err
 	}
-	flight.CarrierCode = carrier
-	flight.FlightNumber = fno
 
 	//Shared fields
-	flight.PeriodOfOperation, err = POOFromString(tokens[1])
+	flight.PeriodOfOperation, err = POOFromStringInSeason(d.period, season)
+	if err != nil {
+		return nil, err
+	}
+	flight.DaysOfOperation, err = ParseDaysOfWeek(d.days)
+	if err != nil {
+		return nil, err
+	}
+	flight.FrequencyRate, err = parseFrequencyRate(d.frequency)
 	if err != nil {
-		return nil, errors.New("ssimparser: period of operation error")
+		return nil, err
 	}
-	flight.DaysOfOperation = tokens[2]
-	cfg, aircraft := getConfAndAicraft(tokens[3])
+	cfg, aircraft := getConfAndAicraft(d.equipment)
 	flight.AircraftType = aircraft
 	flight.Configuration = cfg
 
-	if isDeparture {
-		flight.DepartureAirport = tokens[4][:3]
-		flight.DepartureTimeUTC = tokens[4][3:]
+	if d.departure != "" {
+		flight.Direction = DirectionDeparture
+		flight.ScheduledTime, flight.DayChangeIndicator, flight.AdjacentStation, flight.Station, err = parseDepartureStation(d.departure, false)
 	} else {
-		flight.ArrivalAirport = tokens[4][:3]
-		flight.ArrivalTimeUTC = tokens[4][3:]
+		flight.Direction = DirectionArrival
+		flight.Station, flight.AdjacentStation, flight.ScheduledTime, err = parseArrivalStation(d.arrival)
 	}
-	flight.RawDataLine = line
-	flight.LineNumber = lineNumber
-
-	//TODO: ADD aircrafttype and servicetype fields
+	if err != nil {
+		return nil, err
+	}
+	flight.ServiceType = ServiceType(d.serviceTypes)
+	flight.Supplementary = ParseSupplementaryData(d.supplementary)
 
 	return flight, nil
 }
 
-func getFlightDetail(str string) (string, string, error) {
-	// Test case with three last digits being flight number
-	carrier := str[:len(str)-3]
-	digits := "0123456789"
-	if len(carrier) >= 3 && strings.ContainsAny(carrier, digits) {
-		return str[:2], str[2:], nil
+// parseArrivalStation splits the arrival element of a data line:
+// origin station, optional previous station and the arrival time at the coordinated airport
+// e.g. KIX0500 or JFKLHR0500. Without a previous station the origin is returned as previous station.
+func parseArrivalStation(tok string) (origin string, previous string, arrivalTime string, err error) {
+	switch len(tok) {
+	case 7:
+		origin, previous, arrivalTime = tok[:3], tok[:3], tok[3:]
+	case 10:
+		origin, previous, arrivalTime = tok[:3], tok[3:6], tok[6:]
+	default:
+		return "", "", "", fmt.Errorf("%w: invalid arrival element, expected SSS[SSS]HHMM but have %v", ErrBadStation, tok)
 	}
-	if len(carrier) < 3 && !strings.ContainsAny(carrier, digits) {
-		return str[:2], str[2:], nil
+	if !isStationCode(origin) || !isStationCode(previous) || !isTimeHHMM(arrivalTime) {
+		return "", "", "", fmt.Errorf("%w: invalid arrival element, expected SSS[SSS]HHMM but have %v", ErrBadStation, tok)
 	}
-	return "", "", errors.New("ssimparser: couldn't parse flight details")
+	return origin, previous, arrivalTime, nil
 }
+
+// parseDepartureStation splits the departure element of a data line:
+// departure time at the coordinated airport, optional overnight indicator, optional next station
+// and the destination station e.g. 0030KIX, 00301KIX or 0030LHRJFK.
+// The overnight indicator is the number of days the departure leaves after the arrival of the
+// turnaround, 0 when absent, and is only accepted on turnaround lines.
+// Without a next station the destination is returned as next station.
+func parseDepartureStation(tok string, turnaround bool) (departureTime string, overnight int, next string, destination string, err error) {
+	if len(tok) == 8 || len(tok) == 11 {
+		if !turnaround {
+			return "", 0, "", "", fmt.Errorf("%w: overnight indicator without a turnaround in departure element %v", ErrBadStation, tok)
+		}
+		if !isDigit(tok[4]) {
+			return "", 0, "", "", fmt.Errorf("%w: invalid overnight indicator in departure element %v", ErrBadStation, tok)
+		}
+		overnight = int(tok[4] - '0')
+		tok = tok[:4] + tok[5:]
+	}
+	switch len(tok) {
+	case 7:
+		departureTime, next, destination = tok[:4], tok[4:], tok[4:]
+	case 10:
+		departureTime, next, destination = tok[:4], tok[4:7], tok[7:]
+	default:
+		return "", 0, "", "", fmt.Errorf("%w: invalid departure element, expected HHMM[D][SSS]SSS but have %v", ErrBadStation, tok)
+	}
+	if !isStationCode(next) || !isStationCode(destination) || !isTimeHHMM(departureTime) {
+		return "", 0, "", "", fmt.Errorf("%w: invalid departure element, expected HHMM[D][SSS]SSS but have %v", ErrBadStation, tok)
+	}
+	return departureTime, overnight, next, destination, nil
+}
+
+// parseFrequencyRate reads the frequency rate element e.g. /2 for every second week.
+// An empty element means the series operates every week and gives 0.
+func parseFrequencyRate(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if !isFrequencyRateShape(s) || s[1] < '2' {
+		return 0, fmt.Errorf("%w: invalid frequency rate, expected /2 to /9 but have %v", ErrMalformedLine, s)
+	}
+	return int(s[1] - '0'), nil
+}
+
+// isStationCode reports whether s is an IATA 3-letter location code
+func isStationCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isTimeHHMM reports whether s is a 24h time in HHMM format
+func isTimeHHMM(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	// Atoi alone would accept a sign e.g. +100
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	hh, _ := strconv.Atoi(s[:2])
+	mm, _ := strconv.Atoi(s[2:])
+	return hh <= 23 && mm <= 59
+}
+
+// appendInfoLine adds the content of a single SI or GI line to the info collected so far.
+// Consecutive lines are kept apart with a newline so the encoder can write them back one per line.
+func appendInfoLine(info string, content string) string {
+	content = strings.TrimSpace(content)
+	if info == "" {
+		return content
+	}
+	return info + "\n" + content
+}
+
 func getConfAndAicraft(str string) (string, string) {
 	// conf-seat/aicraft code map is always six digit and in form XXXYYY
 	//capacity-conf is always padded with zeros if necessary
@@ -196,28 +355,34 @@ func getConfAndAicraft(str string) (string, string) {
 
 // Helper function to create PeriodOfOperation from string
 // Extensive validation inside
-// Example: 01JAN31JAN -> PeriodOfOperation{EffectiveDate: "01JAN", TerminationDate: "31JAN", DurationDays: 31}
+// Example: 01JAN31JAN -> PeriodOfOperation{EffectiveDate: "01JAN", TerminationDate: "31JAN", DurationDays: 30}
+// Dates are resolved against season (e.g. S23, W25), so winter periods like 26OCT28MAR span the year boundary.
+// Without a season the leap reference year is used and a termination date before the effective date
+// is moved into the following year.
 
-func poocreator(s string) (*PeriodOfOperation, error) {
+func poocreator(s string, season Season) (*PeriodOfOperation, error) {
 	// Check if string length is valid (10) because DDMMMDDMMM - 2+3+2+3 = 10
 	if len(s) != 10 {
-		return nil, errors.New(fmt.Sprintf("ssimparser: invalid period of operation string length, expected 10 characters but have %v", len(s)))
+		return nil, fmt.Errorf("%w: invalid period of operation string length, expected 10 characters but have %v", ErrBadPeriod, len(s))
 	}
 	// Check format of string DDMMMDDMMM
 	if !isDateDDMMM(s[:5]) || !isDateDDMMM(s[5:]) {
-		return nil, errors.New(fmt.Sprintf("ssimparser: invalid period of operation format, expected DDMMMDDMMM but have %v", s))
+		return nil, fmt.Errorf("%w: invalid period of operation format, expected DDMMMDDMMM but have %v", ErrBadPeriod, s)
 	}
 	// Check if valid date range - first DDMMM must be before or equal to second DDMMM
-	fromDate, err := convertDDMMMtoDate(s[0:5])
+	fromDate, err := convertDDMMMtoDate(s[0:5], season.yearOfMonth(monthNumber(s[2:5])))
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("ssimparser: invalid period of operation format, expected DDMMM but have %v", s[0:4]))
+		return nil, fmt.Errorf("%w: invalid period of operation format, expected DDMMM but have %v", ErrBadPeriod, s[0:5])
+	}
+	toDate, err := convertDDMMMtoDate(s[5:], season.yearOfMonth(monthNumber(s[7:10])))
+	if err == nil && season.IsZero() && fromDate.After(toDate) {
+		toDate, err = convertDDMMMtoDate(s[5:], referenceYear+1)
 	}
-	toDate, err := convertDDMMMtoDate(s[5:])
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("ssimparser: invalid period of operation format, expected DDMMM but have %v", s[5:]))
+		return nil, fmt.Errorf("%w: invalid period of operation format, expected DDMMM but have %v", ErrBadPeriod, s[5:])
 	}
 	if fromDate.After(toDate) {
-		return nil, errors.New(fmt.Sprintf("ssimparser: invalid period of operation format, expected left DDMMM before or equal right DDMMM, but have %v", s))
+		return nil, fmt.Errorf("%w: invalid period of operation format, expected left DDMMM before or equal right DDMMM, but have %v", ErrBadPeriod, s)
 	}
 	duration := DaysBetween(toDate, fromDate)
 
@@ -226,16 +391,18 @@ func poocreator(s string) (*PeriodOfOperation, error) {
 		EffectiveDate:   s[0:5],
 		TerminationDate: s[5:],
 		DurationDays:    duration,
+		Effective:       fromDate,
+		Termination:     toDate,
 	}, nil
 
 }
 
-func convertDDMMMtoDate(s string) (time.Time, error) {
-	// DDMMM to number conversion
-	day, err := strconv.Atoi(s[:2])
-	if err != nil {
-		return time.Time{}, errors.New(fmt.Sprintf("ssimparser: invalid day in DDMMM, expected integer but have %v", s[:3]))
-	}
+// referenceYear is used to resolve DDMMM dates when no season is known.
+// It is a leap year so that 29FEB is accepted.
+const referenceYear = 2000
+
+// monthNumber maps three-letter SSIM month to its number, 0 when unknown
+func monthNumber(month string) int {
 	monthMap := map[string]int{
 		"JAN": 1,
 		"FEB": 2,
@@ -250,14 +417,23 @@ func convertDDMMMtoDate(s string) (time.Time, error) {
 		"NOV": 11,
 		"DEC": 12,
 	}
-	month := monthMap[s[2:]]
+	return monthMap[month]
+}
+
+func convertDDMMMtoDate(s string, year int) (time.Time, error) {
+	// DDMMM to number conversion
+	day, err := strconv.Atoi(s[:2])
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("ssimparser: invalid day in DDMMM, expected integer but have %v", s[:2]))
+	}
+	month := monthNumber(s[2:])
 	if month == 0 {
 		return time.Time{}, errors.New(fmt.Sprintf("ssimparser: invalid month in DDMMM, expected month but have %v", s[2:5]))
 	}
-	layout := "02012006"
-	t, err := time.Parse(layout, fmt.Sprintf("%v%v2006", day, month))
-	if err != nil {
-		return time.Time{}, fmt.Errorf("ssimparser: invalid DDMMM, expected date (DDMMM) but have %v", s)
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	// time.Date normalises overflowing days e.g. 31APR -> 01MAY
+	if t.Day() != day || t.Month() != time.Month(month) {
+		return time.Time{}, fmt.Errorf("ssimparser: invalid DDMMM, %v does not exist in %d", s, year)
 	}
 	return t, nil
 }
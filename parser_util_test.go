@@ -0,0 +1,148 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoocreatorSeasonYears(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		period      string
+		season      Season
+		effective   time.Time
+		termination time.Time
+		duration    int
+	}{
+		{"26MAR28OCT", Season{Type: Summer, Year: 2023}, day(2023, time.March, 26), day(2023, time.October, 28), 216},
+		{"01JAN01JAN", Season{Type: Winter, Year: 2025}, day(2026, time.January, 1), day(2026, time.January, 1), 0},
+		{"15DEC15DEC", Season{Type: Winter, Year: 2025}, day(2025, time.December, 15), day(2025, time.December, 15), 0},
+		{"26OCT30MAR", Season{Type: Winter, Year: 2025}, day(2025, time.October, 26), day(2026, time.March, 30), 155},
+		{"29FEB29FEB", Season{Type: Winter, Year: 2023}, day(2024, time.February, 29), day(2024, time.February, 29), 0},
+		{"01FEB29FEB", Season{}, day(referenceYear, time.February, 1), day(referenceYear, time.February, 29), 28},
+		{"26OCT30MAR", Season{}, day(referenceYear, time.October, 26), day(referenceYear+1, time.March, 30), 155},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period+" "+tt.season.String(), func(t *testing.T) {
+			poo, err := poocreator(tt.period, tt.season)
+			if err != nil {
+				t.Fatalf("poocreator: %v", err)
+			}
+			if !poo.Effective.Equal(tt.effective) || !poo.Termination.Equal(tt.termination) {
+				t.Errorf("got %v - %v, want %v - %v", poo.Effective, poo.Termination, tt.effective, tt.termination)
+			}
+			if poo.DurationDays != tt.duration {
+				t.Errorf("DurationDays = %d, want %d", poo.DurationDays, tt.duration)
+			}
+		})
+	}
+}
+
+func TestPoocreatorRejects(t *testing.T) {
+	tests := []struct {
+		period string
+		season Season
+	}{
+		{"29FEB29FEB", Season{Type: Winter, Year: 2024}}, // February 2025
+		{"31APR31APR", Season{Type: Summer, Year: 2025}},
+		{"28OCT26MAR", Season{Type: Summer, Year: 2023}},
+		{"26MAR28OC", Season{Type: Summer, Year: 2023}},
+		{"26XYZ28OCT", Season{Type: Summer, Year: 2023}},
+	}
+	for _, tt := range tests {
+		if _, err := poocreator(tt.period, tt.season); err == nil {
+			t.Errorf("poocreator(%q, %v) succeeded, want an error", tt.period, tt.season)
+		}
+	}
+}
+
+func TestParseArrivalStation(t *testing.T) {
+	tests := []struct {
+		in                        string
+		origin, previous, arrival string
+		ok                        bool
+	}{
+		{"KIX0500", "KIX", "KIX", "0500", true},
+		{"JFKLHR0500", "JFK", "LHR", "0500", true},
+		{"KIX2400", "", "", "", false},
+		{"KIX0560", "", "", "", false},
+		{"KIX+100", "", "", "", false},
+		{"0500KIX", "", "", "", false},
+		{"KIX05000", "", "", "", false},
+		{"kix0500", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			origin, previous, arrival, err := parseArrivalStation(tt.in)
+			if (err == nil) != tt.ok || origin != tt.origin || previous != tt.previous || arrival != tt.arrival {
+				t.Errorf("parseArrivalStation(%q) = %q, %q, %q, %v", tt.in, origin, previous, arrival, err)
+			}
+		})
+	}
+}
+
+func TestParseDepartureStation(t *testing.T) {
+	tests := []struct {
+		in                           string
+		turnaround                   bool
+		departure, next, destination string
+		overnight                    int
+		ok                           bool
+	}{
+		{"0030KIX", false, "0030", "KIX", "KIX", 0, true},
+		{"0030LHRJFK", false, "0030", "LHR", "JFK", 0, true},
+		{"2359GLA", true, "2359", "GLA", "GLA", 0, true},
+		{"00301KIX", true, "0030", "KIX", "KIX", 1, true},
+		{"00302LHRJFK", true, "0030", "LHR", "JFK", 2, true},
+		{"00301KIX", false, "", "", "", 0, false},
+		{"0030XKIX", true, "", "", "", 0, false},
+		{"-030KIX", false, "", "", "", 0, false},
+		{"3000KIX", false, "", "", "", 0, false},
+		{"KIX0030", false, "", "", "", 0, false},
+		{"0030KI", false, "", "", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			departure, overnight, next, destination, err := parseDepartureStation(tt.in, tt.turnaround)
+			if (err == nil) != tt.ok || departure != tt.departure || overnight != tt.overnight ||
+				next != tt.next || destination != tt.destination {
+				t.Errorf("parseDepartureStation(%q, %v) = %q, %d, %q, %q, %v",
+					tt.in, tt.turnaround, departure, overnight, next, destination, err)
+			}
+		})
+	}
+}
+
+func TestParseTurnaroundLineDirections(t *testing.T) {
+	line := "HLH4123 LH4876 01JUL26JUL 0034507 120319 HAM0700 0750FRA JJ"
+	lexed, err := NewLexer(strings.NewReader(line)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := newDataLine(lexed.Tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := parseTurnaroundLine(d, line, 1, "MUC", Season{Type: Summer, Year: 2025})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	arrival, departure := items[0], items[1]
+	if arrival.Direction != DirectionArrival || arrival.Flight.Number != "4123" ||
+		arrival.Station != "HAM" || arrival.ScheduledTime != "0700" {
+		t.Errorf("arrival = %+v", arrival)
+	}
+	if departure.Direction != DirectionDeparture || departure.Flight.Number != "4876" ||
+		departure.Station != "FRA" || departure.ScheduledTime != "0750" {
+		t.Errorf("departure = %+v", departure)
+	}
+	if arrival.ClearanceAirport != "MUC" || departure.ClearanceAirport != "MUC" {
+		t.Errorf("clearance airport = %q, %q, want MUC", arrival.ClearanceAirport, departure.ClearanceAirport)
+	}
+}
@@ -0,0 +1,107 @@
+package ssimparser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Header reference lines
+//
+//	/ABC123              creator reference - the sender's own reference for the message
+//	REYT/15OCT25/ABC123  reply reference - the message being answered, by date and/or reference ID
+//
+// A coordinator reply carries REYT with the creator reference of the airline request,
+// which ties the two messages together (see SCRMessage.IsReplyTo).
+
+type ReferenceType string
+
+const (
+	ReferenceCreator ReferenceType = "/"
+	ReferenceReply   ReferenceType = "REYT"
+)
+
+type Reference struct {
+	Type ReferenceType
+	Date time.Time // zero when the line carries no DDMMMYY date
+	ID   string
+	Raw  string // line as written in the message
+}
+
+// ParseReference decodes a creator (/ABC123) or reply (REYT/15OCT25/ABC123) reference line
+func ParseReference(s string) (*Reference, error) {
+	s = strings.TrimSpace(s)
+	ref := &Reference{Raw: s}
+	var rest string
+	switch {
+	case strings.HasPrefix(s, string(ReferenceReply)+"/"):
+		ref.Type = ReferenceReply
+		rest = s[len(ReferenceReply)+1:]
+	case strings.HasPrefix(s, string(ReferenceCreator)):
+		ref.Type = ReferenceCreator
+		rest = s[1:]
+	default:
+		return nil, fmt.Errorf("ssimparser: unknown reference line %v", s)
+	}
+
+	for _, part := range strings.Split(rest, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if ref.Date.IsZero() {
+			if date, err := ParseDateDDMMMYY(part); err == nil {
+				ref.Date = date
+				continue
+			}
+		}
+		if ref.ID == "" {
+			ref.ID = part
+		}
+	}
+	if ref.Date.IsZero() && ref.ID == "" {
+		return nil, fmt.Errorf("ssimparser: reference line %v has neither date nor reference ID", s)
+	}
+	return ref, nil
+}
+
+// String returns the line as written, or a canonical form for references built in code
+func (r Reference) String() string {
+	if r.Raw != "" {
+		return r.Raw
+	}
+	if r.Type == ReferenceCreator {
+		return string(ReferenceCreator) + r.ID
+	}
+	date := ""
+	if !r.Date.IsZero() {
+		date = formatDDMMMYY(r.Date)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Type, date, r.ID)
+}
+
+// IsReplyTo reports whether msg answers request.
+// The reply reference ID must match the creator reference of the request; when the reply
+// only carries a date it must match the request message date at the same airport.
+func (msg *SCRMessage) IsReplyTo(request *SCRMessage) bool {
+	if msg.ReplyReference == nil || request == nil {
+		return false
+	}
+	if msg.ReplyReference.ID != "" {
+		return request.CreatorReference != nil && request.CreatorReference.ID == msg.ReplyReference.ID
+	}
+	if msg.ReplyReference.Date.IsZero() || msg.AirportCode != request.AirportCode {
+		return false
+	}
+	return formatDDMMM(msg.ReplyReference.Date) == request.MessageDate
+}
+
+// formatDDMMM writes a date in SSIM DDMMM form e.g. 05OCT
+func formatDDMMM(t time.Time) string {
+	return strings.ToUpper(t.Format("02Jan"))
+}
+
+// formatDDMMMYY writes a date in SSIM DDMMMYY form e.g. 05OCT25
+func formatDDMMMYY(t time.Time) string {
+	return strings.ToUpper(t.Format("02Jan06"))
+}
@@ -0,0 +1,86 @@
+package ssimparser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReference(t *testing.T) {
+	oct15 := time.Date(2025, time.October, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want Reference
+		ok   bool
+	}{
+		{"/ABC123", Reference{Type: ReferenceCreator, ID: "ABC123", Raw: "/ABC123"}, true},
+		{"REYT/15OCT25/ABC123", Reference{Type: ReferenceReply, Date: oct15, ID: "ABC123", Raw: "REYT/15OCT25/ABC123"}, true},
+		{"REYT/15OCT25/", Reference{Type: ReferenceReply, Date: oct15, Raw: "REYT/15OCT25/"}, true},
+		{"REYT//XYZ987", Reference{Type: ReferenceReply, ID: "XYZ987", Raw: "REYT//XYZ987"}, true},
+		{"REYT/", Reference{}, false},
+		{"/", Reference{}, false},
+		{"ABC123", Reference{}, false},
+		{"REYT15OCT25", Reference{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseReference(tt.in)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ParseReference(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			}
+			if tt.ok && *got != tt.want {
+				t.Errorf("ParseReference(%q) = %+v, want %+v", tt.in, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceString(t *testing.T) {
+	built := Reference{Type: ReferenceReply, Date: time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC), ID: "XYZ987"}
+	if got := built.String(); got != "REYT/01MAY23/XYZ987" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Reference{Type: ReferenceCreator, ID: "ABC123"}).String(); got != "/ABC123" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestIsReplyTo(t *testing.T) {
+	request := &SCRMessage{MessageDate: "15OCT", AirportCode: "KRK", CreatorReference: &Reference{Type: ReferenceCreator, ID: "ABC123"}}
+	oct15 := time.Date(2025, time.October, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		reply *SCRMessage
+		want  bool
+	}{
+		{"matching ID", &SCRMessage{AirportCode: "KRK", ReplyReference: &Reference{Type: ReferenceReply, ID: "ABC123"}}, true},
+		{"other ID", &SCRMessage{AirportCode: "KRK", ReplyReference: &Reference{Type: ReferenceReply, ID: "XYZ987"}}, false},
+		{"ID wins over date", &SCRMessage{AirportCode: "KRK", ReplyReference: &Reference{Type: ReferenceReply, Date: oct15, ID: "XYZ987"}}, false},
+		{"date only", &SCRMessage{AirportCode: "KRK", ReplyReference: &Reference{Type: ReferenceReply, Date: oct15}}, true},
+		{"date at other airport", &SCRMessage{AirportCode: "WAW", ReplyReference: &Reference{Type: ReferenceReply, Date: oct15}}, false},
+		{"other date", &SCRMessage{AirportCode: "KRK", ReplyReference: &Reference{Type: ReferenceReply, Date: oct15.AddDate(0, 0, 1)}}, false},
+		{"no reply reference", &SCRMessage{AirportCode: "KRK"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.reply.IsReplyTo(request); got != tt.want {
+				t.Errorf("IsReplyTo = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if (&SCRMessage{ReplyReference: &Reference{Type: ReferenceReply, ID: "ABC123"}}).IsReplyTo(nil) {
+		t.Error("IsReplyTo(nil) = true")
+	}
+}
+
+func TestParseHeaderReferences(t *testing.T) {
+	message := parseSCR(t, "SCR\n/ABC123\nS23\n01MAY\nICN\nREYT/01MAY23/XYZ987\nKAB457 26MAR28OCT 0204060 189738 KIX0500 J\n")
+	if message.CreatorReference == nil || message.CreatorReference.ID != "ABC123" {
+		t.Errorf("CreatorReference = %+v, want ABC123", message.CreatorReference)
+	}
+	if message.ReplyReference == nil || message.ReplyReference.ID != "XYZ987" || message.ReplyReference.Date.Day() != 1 {
+		t.Errorf("ReplyReference = %+v, want 01MAY23 XYZ987", message.ReplyReference)
+	}
+	if len(message.AdministrativeLines) != 0 {
+		t.Errorf("AdministrativeLines = %q, want the references decoded", message.AdministrativeLines)
+	}
+}
@@ -0,0 +1,119 @@
+package ssimparser
+
+import (
+	"fmt"
+	"io"
+)
+
+// Slot Allocation List, sent by the coordinator at the start of a season with the slots held
+//
+//	SAL
+//	/ABC123                                      optional creator reference
+//	W25
+//	01SEP
+//	KRK
+//	LO                                           optional carrier the list is addressed to
+//	K LO012 26OCT28MAR 1234567 189738 0930WAW J
+//	SI ...
+//
+// The data lines share the SCR grammar.
+
+type SALMessage struct {
+	SCRMessage
+	// Carrier is the airline of the carrier header line, empty for a list covering the whole airport
+	Carrier string
+}
+
+// ParseSAL reads a complete SAL message with the settings of the parser (see Parse).
+// A message with another identifier fails with ErrUnexpectedMessage.
+// The originator is the coordinator unless the parser declares one.
+func (scr *ScrParser) ParseSAL(r io.Reader) (*SALMessage, error) {
+	message, err := scr.parseMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	if message.Identifier != "SAL" {
+		return nil, NewParserError("expected SAL identifier", 0, message.Identifier, ErrUnexpectedMessage, Critical)
+	}
+	if scr.originator == OriginatorUnknown {
+		message.Originator = OriginatorCoordinator
+	}
+
+	sal := &SALMessage{SCRMessage: *message}
+	// The carrier is the first administrative line holding an airline designator,
+	// a 3-letter carrier looks like an airport and ends up there as well
+	for i, line := range message.AdministrativeLines {
+		if (len(line) == 2 || len(line) == 3) && isAirlineDesignator(line) {
+			sal.Carrier = line
+			sal.AdministrativeLines = append(append(make([]string, 0, len(message.AdministrativeLines)-1),
+				message.AdministrativeLines[:i]...), message.AdministrativeLines[i+1:]...)
+			break
+		}
+	}
+	return sal, nil
+}
+
+// MarshalText encodes the list as SAL text, the carrier line follows the airport.
+// It makes SALMessage an encoding.TextMarshaler.
+func (sal SALMessage) MarshalText() ([]byte, error) {
+	message := sal.SCRMessage
+	if message.Identifier == "" {
+		message.Identifier = "SAL"
+	}
+	if sal.Carrier != "" {
+		message.AdministrativeLines = append([]string{sal.Carrier}, sal.AdministrativeLines...)
+	}
+	return message.MarshalText()
+}
+
+// Holdings returns the slots held by carrier as a baseline schedule, ready for Diff,
+// ScheduleMessage.Apply or SSIMFromSlots. An empty carrier stands for the SAL carrier,
+// or for every carrier when the list covers the whole airport.
+// Items are copies, a turnaround is split when only one side belongs to carrier.
+func (sal *SALMessage) Holdings(carrier string) *SCRMessage {
+	if carrier == "" {
+		carrier = sal.Carrier
+	}
+	holdings := &SCRMessage{
+		Identifier:          "SCR",
+		Season:              sal.Season,
+		MessageDate:         sal.MessageDate,
+		AirportCode:         sal.AirportCode,
+		Originator:          OriginatorCoordinator,
+		AdministrativeLines: make([]string, 0),
+		Items:               make([]*SlotItem, 0, len(sal.Items)),
+	}
+	for _, line := range dataLines(sal.Items) {
+		held := make([]*SlotItem, 0, len(line))
+		for _, item := range line {
+			if holdsSlot(item.ActionCode) && (carrier == "" || item.Flight.Airline == carrier) {
+				copied := *item
+				held = append(held, &copied)
+			}
+		}
+		if len(held) == 1 {
+			held[0].Turnaround = false
+		}
+		holdings.Items = append(holdings.Items, held...)
+	}
+	holdings.Changes = LinkChanges(holdings.Items)
+	return holdings
+}
+
+// ValidateSAL validates an already parsed SALMessage and adds issues to the validator
+func (pv *ParsingValidator) ValidateSAL(message *SALMessage) {
+	if message.Identifier != "SAL" {
+		pv.AddError(NewParserError("missing SAL identifier", 0, "", nil, Critical))
+	}
+	pv.validateMessage(&message.SCRMessage)
+	for _, item := range message.Items {
+		if !holdsSlot(item.ActionCode) {
+			msg := fmt.Sprintf("action code %v does not describe a held slot", item.ActionCode)
+			pv.AddError(NewParserError(msg, item.LineNumber, item.RawDataLine, nil, Major))
+		}
+		if message.Carrier != "" && item.Flight.Airline != message.Carrier {
+			msg := fmt.Sprintf("flight %v does not belong to carrier %v", item.Flight, message.Carrier)
+			pv.AddError(NewParserError(msg, item.LineNumber, item.RawDataLine, nil, Major))
+		}
+	}
+}
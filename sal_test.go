@@ -0,0 +1,140 @@
+package ssimparser
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSAL(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		airport string
+		carrier string
+		items   int
+	}{
+		{
+			name:    "carrier list",
+			message: "SAL\n/ABC1\nW25\n01SEP\nKRK\nLO\nKLO011 LO012 26OCT28MAR 1234567 189738 WAW0800 0930WAW JJ\nSI TEST\n",
+			airport: "KRK",
+			carrier: "LO",
+			items:   2,
+		},
+		{
+			name:    "3-letter carrier",
+			message: "SAL\nW25\n01SEP\nKRK\nLOT\nK FR012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			airport: "KRK",
+			carrier: "LOT",
+			items:   1,
+		},
+		{
+			name:    "San Salvador",
+			message: "SAL\nW25\n01SEP\nSAL\nK FR012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			airport: "SAL",
+			items:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sal, err := NewScrParser().ParseSAL(strings.NewReader(tt.message))
+			if err != nil {
+				t.Fatalf("ParseSAL: %v", err)
+			}
+			if sal.AirportCode != tt.airport || sal.Carrier != tt.carrier || len(sal.Items) != tt.items {
+				t.Errorf("airport %q carrier %q items %d, want %q %q %d", sal.AirportCode, sal.Carrier, len(sal.Items), tt.airport, tt.carrier, tt.items)
+			}
+			if sal.Originator != OriginatorCoordinator {
+				t.Errorf("originator = %v, want Coordinator", sal.Originator)
+			}
+		})
+	}
+	if _, err := NewScrParser().ParseSAL(strings.NewReader("SCR\nW25\n01SEP\nKRK\n")); !errors.Is(err, ErrUnexpectedMessage) {
+		t.Errorf("SCR message: got %v, want ErrUnexpectedMessage", err)
+	}
+	if _, err := NewScrParser().Parse(strings.NewReader("SAL\nW25\n01SEP\nKRK\nK LO012 26OCT28MAR 1234567 189738 0930WAW J\n")); !errors.Is(err, ErrUnexpectedMessage) {
+		t.Errorf("SAL parsed as SCR: got %v, want ErrUnexpectedMessage", err)
+	}
+}
+
+func TestSALRoundTrip(t *testing.T) {
+	message := "SAL\n/ABC1\nW25\n01SEP\nKRK\nLO\nKLO011 LO012 26OCT28MAR 1234567 189738 WAW0800 0930WAW JJ\nK LO014 26OCT28MAR 1234567 189738 1130WAW J\nSI TEST\n"
+	sal, err := NewScrParser().ParseSAL(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseSAL: %v", err)
+	}
+	text, err := sal.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText: %v", err)
+	}
+	if string(text) != message {
+		t.Errorf("MarshalText =\n%s\nwant\n%s", text, message)
+	}
+	reparsed, err := NewScrParser().ParseSAL(bytes.NewReader(text))
+	if err != nil {
+		t.Fatalf("ParseSAL(MarshalText): %v\n%s", err, text)
+	}
+	if reparsed.Carrier != sal.Carrier || len(reparsed.AdministrativeLines) != 0 {
+		t.Errorf("carrier %q administrative %q, want %q and none", reparsed.Carrier, reparsed.AdministrativeLines, sal.Carrier)
+	}
+	if !reflect.DeepEqual(comparableItems(reparsed.Items), comparableItems(sal.Items)) {
+		t.Errorf("items differ after the round trip\n%s", text)
+	}
+}
+
+func TestParseSCRSanSalvador(t *testing.T) {
+	message := parseSCR(t, "SCR\nS25\n01MAR\nSAL\nN TA123 30MAR25OCT 1234567 180320 0930GUA J\n")
+	if message.AirportCode != "SAL" || len(message.AdministrativeLines) != 0 {
+		t.Fatalf("airport %q administrative %q, want SAL and none", message.AirportCode, message.AdministrativeLines)
+	}
+	if message.Items[0].ClearanceAirport != "SAL" {
+		t.Errorf("clearance airport = %q, want SAL", message.Items[0].ClearanceAirport)
+	}
+}
+
+func TestHoldings(t *testing.T) {
+	sal, err := NewScrParser().ParseSAL(strings.NewReader("SAL\nW25\n01SEP\nKRK\n" +
+		"KLO011 FR012 26OCT28MAR 1234567 189738 WAW0800 0930WAW JJ\n" +
+		"K FR014 26OCT28MAR 1234567 189738 1130WAW J\n" +
+		"X FR016 26OCT28MAR 1234567 189738 1230WAW J\n"))
+	if err != nil {
+		t.Fatalf("ParseSAL: %v", err)
+	}
+	holdings := sal.Holdings("FR")
+	want := "K FR012 26OCT28MAR 1234567 189738 0930WAW J\nK FR014 26OCT28MAR 1234567 189738 1130WAW J"
+	if got := strings.Join(encodedDataLines(t, holdings), "\n"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if !sal.Items[1].Turnaround {
+		t.Error("Holdings changed the SAL items")
+	}
+}
+
+func TestExportHoldings(t *testing.T) {
+	sal, err := NewScrParser().ParseSAL(strings.NewReader("SAL\nW25\n01SEP\nKRK\nLO\n" +
+		"KLO011 LO012 26OCT28MAR 1234567 189738 WAW0800 0930WAW JJ\n"))
+	if err != nil {
+		t.Fatalf("ParseSAL: %v", err)
+	}
+	holdings := sal.Holdings("LO")
+	file, err := SSIMFromSlots(holdings.Items, holdings.Season, func(from, to string) time.Duration { return time.Hour })
+	if err != nil {
+		t.Fatalf("SSIMFromSlots: %v", err)
+	}
+	want := []string{
+		"LO11 26OCT28MAR 1234567 WAW0700 KRK0800+0 LO12",
+		"LO12 26OCT28MAR 1234567 KRK0930 WAW1030+0",
+	}
+	read := writeAndRead(t, file)
+	if len(read.Carriers) != 1 || read.Carriers[0].Airline != "LO" || len(read.Carriers[0].Legs) != len(want) {
+		t.Fatalf("got %+v, want one LO carrier with %d legs", read.Carriers, len(want))
+	}
+	for i, leg := range read.Carriers[0].Legs {
+		if legString(leg) != want[i] {
+			t.Errorf("leg %d = %s, want %s", i, legString(leg), want[i])
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package ssimparser
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Apply returns the schedule at the airport of schedule after the changes of m.
+// The result holds one N line per series, comparing it with the last submitted SCR
+// (see Diff) shows the slot changes the schedule message implies.
+//
+// Times are taken as UTC, messages in local time are rejected. When a change has no leg
+// touching the airport the movements are looked up on the flight date itself.
+// Schedule message dates are the days the movements operate, a departure with an
+// overnight indicator is found and written back as a singular line on the day it actually leaves.
+// RIN only reinstates flights cancelled earlier in the same message. FLT moves the movements
+// to the new designator, RRT and DIV replace them like RPL and keep the equipment when the message
+// has no equipment line. REV moves the movements from the existing to the revised period.
+// ADM, SKD, ACK and NAC leave the slots unchanged.
+// ASM dates written without year are taken in the season of schedule.
+func (m *ScheduleMessage) Apply(schedule *SCRMessage) (*SCRMessage, error) {
+	if m.TimeMode != "UTC" {
+		return nil, fmt.Errorf("%w: cannot apply %v times to a UTC schedule", ErrBadScheduleMessage, m.TimeMode)
+	}
+	airport := schedule.AirportCode
+	index := newScheduleIndex(schedule.Items)
+	cancelled := make(scheduleIndex)
+
+	for _, change := range m.Changes {
+		if len(change.UndatedDates) > 0 {
+			if schedule.Season.IsZero() {
+				return nil, fmt.Errorf("%w: %v %v has dates without year and the schedule has no season", ErrBadScheduleMessage, change.Action, change.Flight)
+			}
+			resolved := *change
+			if err := resolved.resolveDates(schedule.Season); err != nil {
+				return nil, err
+			}
+			change = &resolved
+		}
+		departure := scheduleKey{Airline: change.Flight.Airline, Number: change.Flight.PaddedNumber(), Direction: DirectionDeparture}
+		arrival := scheduleKey{Airline: change.Flight.Airline, Number: change.Flight.PaddedNumber(), Direction: DirectionArrival}
+		movements := change.movements(airport)
+
+		switch change.Action {
+		case ScheduleActionNew, ScheduleActionReplace, ScheduleActionRoute, ScheduleActionDiversion:
+			keepsEquipment := change.Action == ScheduleActionRoute || change.Action == ScheduleActionDiversion
+			if len(change.Legs) == 0 || (change.AircraftType == "" && !keepsEquipment) {
+				return nil, fmt.Errorf("%w: %v %v needs equipment and leg lines", ErrBadScheduleMessage, change.Action, change.Flight)
+			}
+			for _, date := range change.FlightDates() {
+				var replaced *scheduleEntry
+				if change.Action != ScheduleActionNew {
+					for _, key := range []scheduleKey{departure, arrival} {
+						for _, at := range change.movementDates(key.Direction, date, movements) {
+							if keyDate, entry, ok := index.lookup(key, at); ok {
+								if replaced == nil {
+									replaced = &entry
+								}
+								index.remove(key, keyDate)
+							}
+						}
+					}
+				}
+				for _, mv := range movements {
+					entry := change.entry(airport, mv)
+					if replaced != nil {
+						// keep the designator as the schedule writes it e.g. LO012 for LO12
+						entry.Flight = replaced.Flight
+						if entry.Configuration == "" {
+							entry.Configuration = replaced.Configuration
+						}
+					}
+					if change.AircraftType == "" {
+						// a routing change without equipment line keeps the equipment of the flight
+						if replaced == nil {
+							return nil, fmt.Errorf("%w: %v %v %v adds %v without equipment line", ErrBadScheduleMessage, change.Action, change.Flight, formatDDMMMYY(date), airport)
+						}
+						entry.AircraftType, entry.Configuration, entry.ServiceType = replaced.AircraftType, replaced.Configuration, replaced.ServiceType
+					}
+					index.set(mv.key(change.Flight), date.AddDate(0, 0, mv.variation), entry)
+				}
+			}
+		case ScheduleActionFlight:
+			for _, date := range change.FlightDates() {
+				for _, key := range []scheduleKey{departure, arrival} {
+					renamed := scheduleKey{Airline: change.NewFlight.Airline, Number: change.NewFlight.PaddedNumber(), Direction: key.Direction}
+					for _, at := range change.movementDates(key.Direction, date, movements) {
+						if keyDate, entry, ok := index.lookup(key, at); ok {
+							index.remove(key, keyDate)
+							entry.Flight = *change.NewFlight
+							index.set(renamed, keyDate, entry)
+						}
+					}
+				}
+			}
+		case ScheduleActionRevise:
+			// the movements keep their entries, only the dates they operate on change
+			moved := make(map[scheduleKey]scheduleEntry)
+			for _, date := range change.FlightDates() {
+				for _, key := range []scheduleKey{departure, arrival} {
+					for _, at := range change.movementDates(key.Direction, date, movements) {
+						if keyDate, entry, ok := index.lookup(key, at); ok {
+							if _, seen := moved[key]; !seen {
+								moved[key] = entry
+							}
+							index.remove(key, keyDate)
+						}
+					}
+				}
+			}
+			for _, date := range change.RevisedFlightDates() {
+				for key, entry := range moved {
+					for _, at := range change.movementDates(key.Direction, date, movements) {
+						index.set(key, at, entry)
+					}
+				}
+			}
+		case ScheduleActionAdmin, ScheduleActionSkeleton, ScheduleActionAcknowledge, ScheduleActionNotAccepted:
+			// nothing a slot line records changes
+		case ScheduleActionCancel:
+			for _, date := range change.FlightDates() {
+				for _, key := range []scheduleKey{departure, arrival} {
+					for _, at := range change.movementDates(key.Direction, date, movements) {
+						if keyDate, entry, ok := index.lookup(key, at); ok {
+							cancelled.set(key, keyDate, entry)
+							index.remove(key, keyDate)
+						}
+					}
+				}
+			}
+		case ScheduleActionReinstate:
+			for _, date := range change.FlightDates() {
+				reinstated := false
+				for _, key := range []scheduleKey{departure, arrival} {
+					for _, at := range change.movementDates(key.Direction, date, movements) {
+						if keyDate, entry, ok := cancelled.lookup(key, at); ok {
+							index.set(key, keyDate, entry)
+							reinstated = true
+						}
+					}
+				}
+				if !reinstated {
+					return nil, fmt.Errorf("%w: RIN %v %v was not cancelled in this message", ErrBadScheduleMessage, change.Flight, formatDDMMMYY(date))
+				}
+			}
+		case ScheduleActionTime:
+			for _, date := range change.FlightDates() {
+				for _, mv := range movements {
+					key := mv.key(change.Flight)
+					at := date.AddDate(0, 0, mv.variation)
+					if keyDate, entry, ok := index.lookup(key, at); ok {
+						entry.ScheduledTime = mv.time
+						index.set(key, keyDate, entry)
+					}
+				}
+			}
+		case ScheduleActionEquipment, ScheduleActionConfiguration:
+			for _, date := range change.FlightDates() {
+				for _, key := range []scheduleKey{departure, arrival} {
+					for _, at := range change.movementDates(key.Direction, date, movements) {
+						keyDate, entry, ok := index.lookup(key, at)
+						if !ok {
+							continue
+						}
+						if change.Action == ScheduleActionEquipment && change.AircraftType != "" {
+							entry.AircraftType = change.AircraftType
+							if change.ServiceType != "" {
+								entry.ServiceType = change.ServiceType
+							}
+						}
+						if change.Configuration != "" {
+							entry.Configuration = slotSeats(change.Configuration)
+						}
+						index.set(key, keyDate, entry)
+					}
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown action %v", ErrBadScheduleMessage, change.Action)
+		}
+	}
+
+	result := &SCRMessage{
+		Identifier:          "SCR",
+		Season:              schedule.Season,
+		MessageDate:         schedule.MessageDate,
+		AirportCode:         airport,
+		Originator:          OriginatorAirline,
+		AdministrativeLines: make([]string, 0),
+		Items:               index.items(ActionNewSlot),
+	}
+	result.Changes = LinkChanges(result.Items)
+	return result, nil
+}
+
+// scheduleMovement is a leg of a schedule change seen from the airport
+type scheduleMovement struct {
+	direction Direction
+	time      string
+	variation int    // days after the flight date
+	station   string // origin for arrivals, destination for departures
+	adjacent  string
+}
+
+func (mv scheduleMovement) key(flight FlightDesignator) scheduleKey {
+	return scheduleKey{Airline: flight.Airline, Number: flight.PaddedNumber(), Direction: mv.direction}
+}
+
+// movements returns the arrivals and departures of the change legs at airport
+func (c *ScheduleChange) movements(airport string) []scheduleMovement {
+	movements := make([]scheduleMovement, 0)
+	if len(c.Legs) == 0 {
+		return movements
+	}
+	origin, destination := c.Legs[0].DepartureStation, c.Legs[len(c.Legs)-1].ArrivalStation
+	for _, leg := range c.Legs {
+		if leg.ArrivalStation == airport {
+			movements = append(movements, scheduleMovement{DirectionArrival, leg.ArrivalTime, leg.ArrivalDateVariation, origin, leg.DepartureStation})
+		}
+		if leg.DepartureStation == airport {
+			movements = append(movements, scheduleMovement{DirectionDeparture, leg.DepartureTime, leg.DepartureDateVariation, destination, leg.ArrivalStation})
+		}
+	}
+	return movements
+}
+
+// movementDates returns the dates at the airport of a direction for a flight date,
+// the flight date itself when no leg of the change touches the airport in that direction
+func (c *ScheduleChange) movementDates(direction Direction, date time.Time, movements []scheduleMovement) []time.Time {
+	dates := make([]time.Time, 0, 1)
+	for _, mv := range movements {
+		if mv.direction == direction {
+			dates = append(dates, date.AddDate(0, 0, mv.variation))
+		}
+	}
+	if len(dates) == 0 {
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// entry builds the schedule entry of a new or replaced movement.
+// The seat count is left empty when the equipment line has no configuration.
+func (c *ScheduleChange) entry(airport string, mv scheduleMovement) scheduleEntry {
+	entry := scheduleEntry{
+		Flight:           c.Flight,
+		ScheduledTime:    mv.time,
+		AircraftType:     c.AircraftType,
+		ServiceType:      c.ServiceType,
+		Station:          mv.station,
+		AdjacentStation:  mv.adjacent,
+		ClearanceAirport: airport,
+	}
+	if c.Configuration != "" {
+		entry.Configuration = slotSeats(c.Configuration)
+	}
+	return entry
+}
+
+func (index scheduleIndex) set(key scheduleKey, date time.Time, entry scheduleEntry) {
+	if index[key] == nil {
+		index[key] = make(map[time.Time]scheduleEntry)
+	}
+	index[key][date] = entry
+}
+
+// lookup finds the entry of a movement operating on date and returns the date it is indexed on.
+// Departures of overnight turnarounds are indexed on the dates they operate, see scheduleIndex.add.
+func (index scheduleIndex) lookup(key scheduleKey, date time.Time) (time.Time, scheduleEntry, bool) {
+	entry, ok := index[key][date]
+	return date, entry, ok
+}
+
+func (index scheduleIndex) remove(key scheduleKey, date time.Time) {
+	delete(index[key], date)
+}
+
+// items writes the index as slot items with the given action code, one per series,
+// ordered by flight, direction and first date
+func (index scheduleIndex) items(code ActionCode) []*SlotItem {
+	items := make([]*SlotItem, 0)
+	for _, key := range mergedKeys(index, nil) {
+		byEntry := make(map[scheduleEntry][]time.Time)
+		for date, entry := range index[key] {
+			byEntry[entry] = append(byEntry[entry], date)
+		}
+		movement := make([]*SlotItem, 0)
+		for entry, dates := range byEntry {
+			for _, series := range compactSeries(dates, 0) {
+				movement = append(movement, entry.item(code, key.Direction, series))
+			}
+		}
+		sort.Slice(movement, func(i, j int) bool {
+			return movement[i].PeriodOfOperation.Effective.Before(movement[j].PeriodOfOperation.Effective)
+		})
+		items = append(items, movement...)
+	}
+	for _, item := range items {
+		item.SlotKey = item.GetSlotKey()
+	}
+	return items
+}
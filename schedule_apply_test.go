@@ -0,0 +1,139 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		message  string
+		want     []string
+	}{
+		{
+			name:     "cancel an overnight departure on its operating date",
+			schedule: "SCR\nW25\n20OCT\nKRK\nNLO011 LO012 26OCT28MAR 1234567 189738 WAW2200 09301WAW JJ\n",
+			message:  "SSM\nUTC\nCNL\nLO12\n03NOV25 03NOV25 1234567\n",
+			want:     []string{"D LO012 03NOV03NOV 1000000 189738 0930WAW J"},
+		},
+		{
+			name:     "ASM cancel and reinstate",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "ASM\nUTC\nCNL\nLO12/01NOV25/02NOV25\n//\nRIN\nLO12/02NOV25\n",
+			want:     []string{"D LO012 01NOV01NOV 0000060 189738 0930WAW J"},
+		},
+		{
+			name:     "new flight",
+			schedule: "SCR\nW25\n20OCT\nKRK\n",
+			message:  "SSM\nUTC\nNEW\nLO10\n26OCT25 28MAR26 1234567\nJ 73H.Y189\nWAW0700 KRK0750\n",
+			want:     []string{"NLO10 26OCT28MAR 1234567 18973H WAW0750 J"},
+		},
+		{
+			name:     "new flight without configuration",
+			schedule: "SCR\nW25\n20OCT\nKRK\n",
+			message:  "SSM\nUTC\nNEW\nLO10\n26OCT25 28MAR26 1234567\nJ 73H\nWAW0700 KRK0750\n",
+			want:     []string{"NLO10 26OCT28MAR 1234567 73H WAW0750 J"},
+		},
+		{
+			name:     "replacement without configuration keeps the seats",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "SSM\nUTC\nRPL\nLO12\n10NOV25 10NOV25 1234567\nJ 73H\nKRK0930 WAW1020\n",
+			want: []string{
+				"C LO012 10NOV10NOV 1000000 189738 0930WAW J",
+				"R LO012 10NOV10NOV 1000000 18973H 0930WAW J",
+			},
+		},
+		{
+			name:     "revised period",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 02NOV29NOV 1234567 189738 0930WAW J\n",
+			message:  "SSM\nUTC\nREV\nLO12\n02NOV25 29NOV25 1234567\n02NOV25 15NOV25 1234567\n",
+			want:     []string{"D LO012 16NOV29NOV 1234567 189738 0930WAW J"},
+		},
+		{
+			name:     "ASM cancel without year",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "ASM\nUTC\nCNL\nLO12/01NOV\n",
+			want:     []string{"D LO012 01NOV01NOV 0000060 189738 0930WAW J"},
+		},
+		{
+			name:     "diversion keeps the equipment",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "ASM\nUTC\nDIV\nLO12/10NOV25\nKRK0930 KTW1010\n",
+			want: []string{
+				"C LO012 10NOV10NOV 1000000 189738 0930WAW J",
+				"R LO012 10NOV10NOV 1000000 189738 0930KTW J",
+			},
+		},
+		{
+			name:     "time change of an overnight departure",
+			schedule: "SCR\nW25\n20OCT\nKRK\nNLO011 LO012 26OCT28MAR 1234567 189738 WAW2200 09301WAW JJ\n",
+			message:  "SSM\nUTC\nTIM\nLO12\n10NOV25 10NOV25 1234567\nKRK1000 WAW1050\n",
+			want: []string{
+				"C LO012 10NOV10NOV 1000000 189738 0930WAW J",
+				"R LO012 10NOV10NOV 1000000 189738 1000WAW J",
+			},
+		},
+		{
+			name:     "administrative change leaves the slots alone",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "SSM\nUTC\nADM\nLO12\n26OCT25 28MAR26 1234567\nJ 738\n//\nCNL\nLO12\n01NOV25 01NOV25 1234567\n",
+			want:     []string{"D LO012 01NOV01NOV 0000060 189738 0930WAW J"},
+		},
+		{
+			name:     "flight designator change",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "SSM\nUTC\nFLT\nLO12\n10NOV25 10NOV25 1234567\nLO14\n",
+			want: []string{
+				"D LO012 10NOV10NOV 1000000 189738 0930WAW J",
+				"N LO14 10NOV10NOV 1000000 189738 0930WAW J",
+			},
+		},
+		{
+			name:     "routing change keeps the equipment",
+			schedule: "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\n",
+			message:  "SSM\nUTC\nRRT\nLO12\n10NOV25 10NOV25 1234567\nKRK0930 GDN1020\n",
+			want: []string{
+				"C LO012 10NOV10NOV 1000000 189738 0930WAW J",
+				"R LO012 10NOV10NOV 1000000 189738 0930GDN J",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := parseSCR(t, tt.schedule)
+			applied, err := parseSchedule(t, tt.message).Apply(schedule)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			diff, err := Diff(schedule, applied)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			got := encodedDataLines(t, diff)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestApplyOvernightRotationReparses(t *testing.T) {
+	schedule := parseSCR(t, "SCR\nW25\n20OCT\nKRK\nNLO011 LO012 26OCT28MAR 1234567 189738 WAW2200 09301WAW JJ\n")
+	applied, err := parseSchedule(t, "ASM\nUTC\nTIM\nLO12/10NOV25\nKRK1000 WAW1050\n").Apply(schedule)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	reparsed := parseSCR(t, encodeMessage(t, applied))
+	got := encodedDataLines(t, reparsed)
+	want := []string{
+		"NLO011 26OCT28MAR 1234567 189738 WAW2200 J",
+		"N LO012 27OCT09NOV 1234567 189738 0930WAW J",
+		"N LO012 10NOV10NOV 1000000 189738 1000WAW J",
+		"N LO012 11NOV29MAR 1234567 189738 0930WAW J",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,437 @@
+package ssimparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Standard (SSM) and ad hoc (ASM) schedule messages
+//
+//	SSM                          message type
+//	UTC                          time mode, UTC or LT
+//	20OCT00001E001/REF123        optional message reference
+//	NEW                          action identifier
+//	LO10                         flight (ASM: LO10/26OCT25, one or more dates)
+//	26OCT25 28MAR26 1234567      period and days (SSM only), /W2 for every second week
+//	J 73H.Y189                   service type, aircraft type and configuration
+//	WAW0700 KRK0750              leg: departure station and time, arrival station and time
+//	//                           next action
+//	CNL
+//	LO12
+//	01NOV25 15NOV25 1234567
+//	SI FREE TEXT                 supplementary information
+//
+// The message reference is DDMMMnnnnn[E]nnn: creation date, group reference and an
+// optional E (last message of the group) with the message serial number, optionally
+// followed by /creator reference.
+// REV gives the existing period on the first period line and the revised one on the next.
+// ACK and NAC answer a message and carry no flight, their lines are kept as they are.
+// An ASM flight date may be written without year (LO10/26OCT), see ScheduleChange.UndatedDates.
+// A leg time may carry a date variation e.g. KRK0150/1 for the day after the flight date.
+// FLT names the new flight designator on its own line after the period (SSM)
+// or as a second element of the flight line (ASM: LO10/26OCT25 LO14/26OCT25).
+
+type ScheduleMessageType string
+
+const (
+	ScheduleMessageSSM ScheduleMessageType = "SSM"
+	ScheduleMessageASM ScheduleMessageType = "ASM"
+)
+
+type ScheduleAction string
+
+const (
+	ScheduleActionNew           ScheduleAction = "NEW" // New flight
+	ScheduleActionCancel        ScheduleAction = "CNL" // Cancellation
+	ScheduleActionReinstate     ScheduleAction = "RIN" // Reinstatement of cancelled flights
+	ScheduleActionTime          ScheduleAction = "TIM" // Time change
+	ScheduleActionEquipment     ScheduleAction = "EQT" // Equipment change
+	ScheduleActionConfiguration ScheduleAction = "CON" // Configuration change
+	ScheduleActionReplace       ScheduleAction = "RPL" // Replacement of the complete flight
+	ScheduleActionFlight        ScheduleAction = "FLT" // Flight designator change
+	ScheduleActionSkeleton      ScheduleAction = "SKD" // Schedule dump
+	ScheduleActionAdmin         ScheduleAction = "ADM" // Administrative change
+	ScheduleActionRoute         ScheduleAction = "RRT" // Routing change
+	ScheduleActionRevise        ScheduleAction = "REV" // Revision of period or frequency
+	ScheduleActionDiversion     ScheduleAction = "DIV" // Diversion (ASM)
+	ScheduleActionAcknowledge   ScheduleAction = "ACK" // Acknowledgement
+	ScheduleActionNotAccepted   ScheduleAction = "NAC" // Message not accepted
+)
+
+func (a ScheduleAction) IsValid() bool {
+	switch a {
+	case ScheduleActionNew, ScheduleActionCancel, ScheduleActionReinstate, ScheduleActionTime,
+		ScheduleActionEquipment, ScheduleActionConfiguration, ScheduleActionReplace,
+		ScheduleActionFlight, ScheduleActionSkeleton, ScheduleActionAdmin, ScheduleActionRoute,
+		ScheduleActionRevise, ScheduleActionDiversion, ScheduleActionAcknowledge, ScheduleActionNotAccepted:
+		return true
+	}
+	return false
+}
+
+// isAnswer reports whether the action answers an earlier message instead of changing flights
+func (a ScheduleAction) isAnswer() bool {
+	return a == ScheduleActionAcknowledge || a == ScheduleActionNotAccepted
+}
+
+type ScheduleMessage struct {
+	Type              ScheduleMessageType
+	TimeMode          string // UTC or LT
+	Reference         string // message reference line, empty when absent
+	Changes           []*ScheduleChange
+	SupplementaryInfo string // SI lines joined with a newline
+}
+
+// ScheduleChange is one action of a schedule message
+type ScheduleChange struct {
+	Action ScheduleAction
+	Flight FlightDesignator
+	// NewFlight is the designator a FLT change renames Flight to
+	NewFlight *FlightDesignator
+	// SSM changes apply to a period and days, ASM changes to single flight dates
+	PeriodOfOperation *PeriodOfOperation
+	DaysOfOperation   DaysOfWeek
+	FrequencyRate     int
+	Dates             []time.Time
+	// UndatedDates holds the ASM dates written without year (DDMMM). They are not part of
+	// Dates, Apply resolves them against the season of the schedule.
+	UndatedDates []string
+	// REV replaces PeriodOfOperation, DaysOfOperation and FrequencyRate with the revised ones
+	RevisedPeriod          *PeriodOfOperation
+	RevisedDaysOfOperation DaysOfWeek
+	RevisedFrequencyRate   int
+	// Equipment line
+	ServiceType   ServiceType
+	AircraftType  string
+	Configuration string // e.g. Y189
+	Legs          []ScheduleLeg
+	// Lines holds the lines that are not decoded e.g. data element lines
+	Lines      []string
+	LineNumber int // line of the action identifier
+}
+
+// ScheduleLeg is a leg line, date variations are days after the flight date
+type ScheduleLeg struct {
+	DepartureStation       string
+	DepartureTime          string // HHMM
+	DepartureDateVariation int
+	ArrivalStation         string
+	ArrivalTime            string // HHMM
+	ArrivalDateVariation   int
+}
+
+// FlightDates enumerates the flight dates the change applies to
+func (c *ScheduleChange) FlightDates() []time.Time {
+	if c.PeriodOfOperation != nil {
+		return operatingDates(c.PeriodOfOperation, c.DaysOfOperation, c.FrequencyRate, 0)
+	}
+	return c.Dates
+}
+
+// RevisedFlightDates enumerates the flight dates of a REV change after the revision
+func (c *ScheduleChange) RevisedFlightDates() []time.Time {
+	if c.RevisedPeriod == nil {
+		return nil
+	}
+	return operatingDates(c.RevisedPeriod, c.RevisedDaysOfOperation, c.RevisedFrequencyRate, 0)
+}
+
+// resolveDates adds the undated ASM dates resolved against season to Dates
+func (c *ScheduleChange) resolveDates(season Season) error {
+	if len(c.UndatedDates) == 0 {
+		return nil
+	}
+	dates := append([]time.Time(nil), c.Dates...)
+	for _, ddmmm := range c.UndatedDates {
+		date, err := convertDDMMMtoDate(ddmmm, season.yearOfMonth(monthNumber(ddmmm[2:])))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBadPeriod, err)
+		}
+		dates = append(dates, date)
+	}
+	c.Dates = dates
+	return nil
+}
+
+// ParseScheduleMessage reads an SSM or ASM.
+// Failures are returned as *ParserError wrapping ErrBadScheduleMessage or one of the field sentinels.
+func ParseScheduleMessage(r io.Reader) (*ScheduleMessage, error) {
+	scanner := bufio.NewScanner(r)
+	message := &ScheduleMessage{Changes: make([]*ScheduleChange, 0)}
+	var (
+		change     *ScheduleChange
+		lineNumber int
+		state      = "type"
+	)
+	fail := func(text, line string, err error) error {
+		return NewParserError(text, lineNumber, line, err, Critical)
+	}
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		switch {
+		case state == "type":
+			messageType := ScheduleMessageType(fields[0])
+			if messageType != ScheduleMessageSSM && messageType != ScheduleMessageASM {
+				return nil, fail("unknown schedule message type", line, ErrBadScheduleMessage)
+			}
+			message.Type = messageType
+			state = "time mode"
+		case state == "time mode":
+			if line != "UTC" && line != "LT" {
+				return nil, fail("expected time mode UTC or LT", line, ErrBadScheduleMessage)
+			}
+			message.TimeMode = line
+			state = "reference"
+		case line == "//":
+			change = nil
+			state = "action"
+		case fields[0] == "SI":
+			message.SupplementaryInfo = appendInfoLine(message.SupplementaryInfo, strings.TrimPrefix(line, "SI"))
+			state = "info"
+		case state == "info":
+			message.SupplementaryInfo = appendInfoLine(message.SupplementaryInfo, line)
+		case state == "reference" && isScheduleReference(line):
+			message.Reference = line
+			state = "action"
+		case state == "reference" || state == "action":
+			action := ScheduleAction(fields[0])
+			if !action.IsValid() {
+				return nil, fail("unknown schedule action", line, ErrBadScheduleMessage)
+			}
+			change = &ScheduleChange{Action: action, Legs: make([]ScheduleLeg, 0), LineNumber: lineNumber}
+			message.Changes = append(message.Changes, change)
+			state = "flight"
+			if action.isAnswer() {
+				state = "answer"
+			}
+		case state == "answer":
+			change.Lines = append(change.Lines, line)
+		case state == "flight":
+			if err := change.parseFlightLine(message.Type, fields); err != nil {
+				return nil, fail("bad flight line", line, err)
+			}
+			state = "data"
+		default:
+			if err := change.parseDataLine(line, fields); err != nil {
+				return nil, fail("bad schedule data line", line, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fail("reading message failed", "", err)
+	}
+	if message.Type == "" {
+		return nil, NewParserError("empty schedule message", 0, "", ErrBadScheduleMessage, Critical)
+	}
+	if message.TimeMode == "" {
+		return nil, NewParserError("missing time mode", lineNumber, "", ErrBadScheduleMessage, Critical)
+	}
+	for _, c := range message.Changes {
+		if c.Action.isAnswer() {
+			continue
+		}
+		if c.Flight.Airline == "" {
+			return nil, NewParserError(fmt.Sprintf("%v action without flight", c.Action), c.LineNumber, "", ErrBadScheduleMessage, Critical)
+		}
+		if message.Type == ScheduleMessageSSM && c.PeriodOfOperation == nil {
+			return nil, NewParserError(fmt.Sprintf("%v %v without period", c.Action, c.Flight), c.LineNumber, "", ErrBadPeriod, Critical)
+		}
+		if c.Action == ScheduleActionRevise && c.RevisedPeriod == nil {
+			return nil, NewParserError(fmt.Sprintf("REV %v without revised period", c.Flight), c.LineNumber, "", ErrBadPeriod, Critical)
+		}
+		if c.Action == ScheduleActionFlight && c.NewFlight == nil {
+			return nil, NewParserError(fmt.Sprintf("FLT %v without new flight designator", c.Flight), c.LineNumber, "", ErrBadScheduleMessage, Critical)
+		}
+	}
+	return message, nil
+}
+
+// parseFlightLine reads LO10 (SSM) or LO10/26OCT25/27OCT25 (ASM).
+// ASM dates without year are kept in UndatedDates.
+func (c *ScheduleChange) parseFlightLine(messageType ScheduleMessageType, fields []string) error {
+	field := fields[0]
+	if c.Action == ScheduleActionFlight && len(fields) > 1 {
+		designator, _, _ := strings.Cut(fields[1], "/")
+		if err := c.parseNewFlight(designator); err != nil {
+			return err
+		}
+	}
+	parts := strings.Split(field, "/")
+	flight, err := ParseFlightDesignator(parts[0])
+	if err != nil {
+		return err
+	}
+	c.Flight = flight
+	if messageType == ScheduleMessageSSM {
+		return nil
+	}
+	if len(parts) < 2 {
+		return fmt.Errorf("%w: ASM flight %v without date", ErrBadScheduleMessage, field)
+	}
+	for _, part := range parts[1:] {
+		if len(part) == 5 && isDateDDMMM(part) {
+			c.UndatedDates = append(c.UndatedDates, part)
+			continue
+		}
+		date, err := ParseDateDDMMMYY(part)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBadPeriod, err)
+		}
+		c.Dates = append(c.Dates, date)
+	}
+	return nil
+}
+
+// parseDataLine decodes period, equipment and leg lines, anything else is kept in Lines
+func (c *ScheduleChange) parseDataLine(line string, fields []string) error {
+	switch {
+	case c.PeriodOfOperation == nil && isPeriodLine(fields):
+		var err error
+		c.PeriodOfOperation, c.DaysOfOperation, c.FrequencyRate, err = parsePeriodLine(fields)
+		return err
+	case c.Action == ScheduleActionRevise && c.RevisedPeriod == nil && isPeriodLine(fields):
+		var err error
+		c.RevisedPeriod, c.RevisedDaysOfOperation, c.RevisedFrequencyRate, err = parsePeriodLine(fields)
+		return err
+	case c.AircraftType == "" && len(fields) >= 2 && len(fields[0]) == 1 && isUpperLetter(fields[0][0]) && len(fields[1]) >= 3 &&
+		(len(fields[1]) == 3 || fields[1][3] == '.'):
+		c.ServiceType = ServiceType(fields[0])
+		c.AircraftType, c.Configuration, _ = strings.Cut(fields[1], ".")
+		if c.Configuration == "" && len(fields) > 2 {
+			c.Configuration = strings.TrimPrefix(fields[2], ".")
+		}
+		return nil
+	case len(fields) >= 2 && isLegElement(fields[0]) && isLegElement(fields[1]):
+		leg := ScheduleLeg{}
+		var err error
+		if leg.DepartureStation, leg.DepartureTime, leg.DepartureDateVariation, err = parseLegElement(fields[0]); err != nil {
+			return err
+		}
+		if leg.ArrivalStation, leg.ArrivalTime, leg.ArrivalDateVariation, err = parseLegElement(fields[1]); err != nil {
+			return err
+		}
+		c.Legs = append(c.Legs, leg)
+		return nil
+	case c.Action == ScheduleActionFlight && c.NewFlight == nil && len(fields) == 1:
+		return c.parseNewFlight(fields[0])
+	}
+	c.Lines = append(c.Lines, line)
+	return nil
+}
+
+func (c *ScheduleChange) parseNewFlight(field string) error {
+	flight, err := ParseFlightDesignator(field)
+	if err != nil {
+		return err
+	}
+	c.NewFlight = &flight
+	return nil
+}
+
+func isPeriodLine(fields []string) bool {
+	return len(fields) >= 3 && len(fields[0]) == 7 && isDateDDMMM(fields[0][:5])
+}
+
+// parsePeriodLine reads 26OCT25 28MAR26 1234567 with an optional /W2 frequency
+func parsePeriodLine(fields []string) (*PeriodOfOperation, DaysOfWeek, int, error) {
+	from, err := ParseDateDDMMMYY(fields[0])
+	if err != nil {
+		return nil, NoDays, 0, fmt.Errorf("%w: %v", ErrBadPeriod, err)
+	}
+	to, err := ParseDateDDMMMYY(fields[1])
+	if err != nil {
+		return nil, NoDays, 0, fmt.Errorf("%w: %v", ErrBadPeriod, err)
+	}
+	if from.After(to) {
+		return nil, NoDays, 0, fmt.Errorf("%w: %v is after %v", ErrBadPeriod, fields[0], fields[1])
+	}
+	dayText, rateText, _ := strings.Cut(fields[2], "/")
+	days, err := parseCompactDays(dayText)
+	if err != nil {
+		return nil, NoDays, 0, err
+	}
+	rate := 0
+	if rateText != "" {
+		if len(rateText) != 2 || rateText[0] != 'W' || rateText[1] < '2' || !isDigit(rateText[1]) {
+			return nil, NoDays, 0, fmt.Errorf("%w: invalid frequency rate %v", ErrBadScheduleMessage, rateText)
+		}
+		rate = int(rateText[1] - '0')
+	}
+	return NewPeriodOfOperation(from, to), days, rate, nil
+}
+
+// isScheduleReference matches the message reference DDMMMnnnnn[E]nnn[/creator reference]
+// e.g. 20OCT00001E001/REF123
+func isScheduleReference(line string) bool {
+	reference, _, _ := strings.Cut(line, "/")
+	if len(reference) < 10 || !isDateDDMMM(reference[:5]) {
+		return false
+	}
+	serial := reference[10:]
+	if len(serial) > 0 && serial[0] == 'E' {
+		serial = serial[1:]
+	}
+	if len(serial) != 0 && len(serial) != 3 {
+		return false
+	}
+	for i := 5; i < 10; i++ {
+		if !isDigit(reference[i]) {
+			return false
+		}
+	}
+	for i := 0; i < len(serial); i++ {
+		if !isDigit(serial[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCompactDays accepts the positional form (1030507) and the compact form used in SSM (1357)
+func parseCompactDays(s string) (DaysOfWeek, error) {
+	if len(s) == 7 {
+		return ParseDaysOfWeek(s)
+	}
+	var days DaysOfWeek
+	for i := 0; i < len(s); i++ {
+		if s[i] < '1' || s[i] > '7' || (i > 0 && s[i] <= s[i-1]) {
+			return NoDays, fmt.Errorf("%w: invalid days of operation %v", ErrBadDaysOfOperation, s)
+		}
+		days |= 1 << (s[i] - '1')
+	}
+	if days == NoDays {
+		return NoDays, fmt.Errorf("%w: empty days of operation", ErrBadDaysOfOperation)
+	}
+	return days, nil
+}
+
+// isLegElement matches a station followed by a time e.g. WAW0700 or KRK0150/1
+func isLegElement(s string) bool {
+	return len(s) >= 7 && isStationCode(s[:3]) && isDigit(s[3])
+}
+
+func parseLegElement(s string) (station, hhmm string, variation int, err error) {
+	station, hhmm = s[:3], s[3:]
+	if before, after, ok := strings.Cut(hhmm, "/"); ok {
+		hhmm = before
+		if after == "M1" {
+			variation = -1
+		} else if variation, err = strconv.Atoi(after); err != nil {
+			return "", "", 0, fmt.Errorf("%w: invalid date variation in %v", ErrBadScheduleMessage, s)
+		}
+	}
+	if !isTimeHHMM(hhmm) {
+		return "", "", 0, fmt.Errorf("%w: invalid time in %v", ErrBadScheduleMessage, s)
+	}
+	return station, hhmm, variation, nil
+}
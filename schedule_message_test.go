@@ -0,0 +1,158 @@
+package ssimparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseScheduleMessage(t *testing.T) {
+	message := parseSchedule(t, `SSM
+UTC
+20OCT00001E001/REF123
+NEW
+LO10
+26OCT25 28MAR26 1234567/W2
+J 73H.Y189
+WAW0700 KRK0750
+KRK2350 GDN0040/1
+//
+CNL
+LO12
+01NOV25 15NOV25 1234567
+SI FREE TEXT
+`)
+	if message.Type != ScheduleMessageSSM || message.TimeMode != "UTC" || message.Reference != "20OCT00001E001/REF123" {
+		t.Errorf("header = %v %v %q", message.Type, message.TimeMode, message.Reference)
+	}
+	if len(message.Changes) != 2 || message.SupplementaryInfo != "FREE TEXT" {
+		t.Fatalf("got %d changes and SI %q", len(message.Changes), message.SupplementaryInfo)
+	}
+	added := message.Changes[0]
+	if added.Action != ScheduleActionNew || added.Flight.String() != "LO10" || added.FrequencyRate != 2 ||
+		added.ServiceType != "J" || added.AircraftType != "73H" || added.Configuration != "Y189" {
+		t.Errorf("NEW = %+v", added)
+	}
+	want := []ScheduleLeg{
+		{DepartureStation: "WAW", DepartureTime: "0700", ArrivalStation: "KRK", ArrivalTime: "0750"},
+		{DepartureStation: "KRK", DepartureTime: "2350", ArrivalStation: "GDN", ArrivalTime: "0040", ArrivalDateVariation: 1},
+	}
+	if len(added.Legs) != len(want) || added.Legs[0] != want[0] || added.Legs[1] != want[1] {
+		t.Errorf("legs = %+v, want %+v", added.Legs, want)
+	}
+	if cancel := message.Changes[1]; cancel.Action != ScheduleActionCancel || len(cancel.FlightDates()) != 15 {
+		t.Errorf("CNL = %+v on %d dates", cancel, len(cancel.FlightDates()))
+	}
+
+	if _, err := ParseScheduleMessage(strings.NewReader("XSM\nUTC\n")); !errors.Is(err, ErrBadScheduleMessage) {
+		t.Errorf("unknown message type: got %v, want ErrBadScheduleMessage", err)
+	}
+}
+
+func TestParseScheduleMessageNewFlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{"SSM new flight line", "SSM\nUTC\nFLT\nLO12\n10NOV25 10NOV25 1234567\nLO14\n"},
+		{"ASM second flight element", "ASM\nUTC\nFLT\nLO12/10NOV25 LO14/10NOV25\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := parseSchedule(t, tt.message).Changes[0]
+			if change.NewFlight == nil || change.NewFlight.String() != "LO14" {
+				t.Errorf("NewFlight = %v, want LO14", change.NewFlight)
+			}
+		})
+	}
+	if _, err := ParseScheduleMessage(strings.NewReader("SSM\nUTC\nFLT\nLO12\n10NOV25 10NOV25 1234567\n")); !errors.Is(err, ErrBadScheduleMessage) {
+		t.Errorf("FLT without new designator: got %v, want ErrBadScheduleMessage", err)
+	}
+}
+
+func TestParseScheduleMessageActions(t *testing.T) {
+	revised := parseSchedule(t, "SSM\nUTC\nREV\nLO12\n01NOV25 30NOV25 1234567\n01NOV25 15NOV25 135/W2\n").Changes[0]
+	if revised.Action != ScheduleActionRevise || len(revised.FlightDates()) != 30 ||
+		revised.RevisedDaysOfOperation.String() != "1030500" || revised.RevisedFrequencyRate != 2 {
+		t.Errorf("REV = %+v", revised)
+	}
+	if _, err := ParseScheduleMessage(strings.NewReader("SSM\nUTC\nREV\nLO12\n01NOV25 30NOV25 1234567\n")); !errors.Is(err, ErrBadPeriod) {
+		t.Errorf("REV without revised period: got %v, want ErrBadPeriod", err)
+	}
+
+	answer := parseSchedule(t, "SSM\nUTC\n20OCT00001E001/REF123\nACK\n")
+	if len(answer.Changes) != 1 || answer.Changes[0].Action != ScheduleActionAcknowledge {
+		t.Errorf("ACK = %+v", answer.Changes)
+	}
+	rejected := parseSchedule(t, "ASM\nUTC\nNAC\n000 UNKNOWN FLIGHT\n").Changes[0]
+	if rejected.Action != ScheduleActionNotAccepted || len(rejected.Lines) != 1 {
+		t.Errorf("NAC = %+v", rejected)
+	}
+
+	diverted := parseSchedule(t, "ASM\nUTC\nDIV\nLO12/10NOV25\nKRK0930 KTW1010\n").Changes[0]
+	if diverted.Action != ScheduleActionDiversion || len(diverted.Legs) != 1 {
+		t.Errorf("DIV = %+v", diverted)
+	}
+}
+
+func TestParseScheduleMessageReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		reference string
+		line      int // line of the error, 0 when the message parses
+	}{
+		{"with group end and creator", "SSM\nUTC\n20OCT00001E001/REF123\nCNL\nLO12\n01NOV25 01NOV25 1234567\n", "20OCT00001E001/REF123", 0},
+		{"serial without group end", "SSM\nUTC\n20OCT00001002\nCNL\nLO12\n01NOV25 01NOV25 1234567\n", "20OCT00001002", 0},
+		{"without reference", "SSM\nUTC\nREV\nLO12\n01NOV25 30NOV25 1234567\n01NOV25 15NOV25 1234567\n", "", 0},
+		{"unknown action is not a reference", "SSM\nUTC\nXYZ\nLO12\n01NOV25 01NOV25 1234567\n", "", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := ParseScheduleMessage(strings.NewReader(tt.message))
+			if tt.line != 0 {
+				var perr *ParserError
+				if !errors.As(err, &perr) || perr.LineNumber != tt.line {
+					t.Fatalf("Parse error = %v, want one at line %d", err, tt.line)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if message.Reference != tt.reference {
+				t.Errorf("Reference = %q, want %q", message.Reference, tt.reference)
+			}
+		})
+	}
+}
+
+func TestParseScheduleMessageMissingTimeMode(t *testing.T) {
+	if _, err := ParseScheduleMessage(strings.NewReader("SSM\n")); !errors.Is(err, ErrBadScheduleMessage) {
+		t.Errorf("got %v, want ErrBadScheduleMessage", err)
+	}
+}
+
+func TestParseScheduleMessageDateWithoutYear(t *testing.T) {
+	change := parseSchedule(t, "ASM\nUTC\nCNL\nLO12/01NOV/02MAR26\n").Changes[0]
+	if len(change.Dates) != 1 || formatDDMMMYY(change.Dates[0]) != "02MAR26" {
+		t.Errorf("Dates = %v, want only the date with year", change.Dates)
+	}
+	if len(change.UndatedDates) != 1 || change.UndatedDates[0] != "01NOV" {
+		t.Errorf("UndatedDates = %q, want [01NOV]", change.UndatedDates)
+	}
+
+	season, _ := ParseSeason("W25")
+	if err := change.resolveDates(season); err != nil {
+		t.Fatalf("resolveDates: %v", err)
+	}
+	want := []string{"02MAR26", "01NOV25"}
+	if len(change.Dates) != len(want) {
+		t.Fatalf("resolved Dates = %v, want %v", change.Dates, want)
+	}
+	for i, date := range change.Dates {
+		if formatDDMMMYY(date) != want[i] {
+			t.Errorf("date %d = %v, want %v", i, formatDDMMMYY(date), want[i])
+		}
+	}
+}
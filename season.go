@@ -0,0 +1,165 @@
+package ssimparser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// IATA scheduling seasons
+//
+// Summer: last Sunday of March until the Saturday before the last Sunday of October
+// Winter: last Sunday of October until the Saturday before the last Sunday of March of the next year
+//
+// A season always starts on a Sunday and ends on a Saturday, so it is made of whole weeks.
+// In SCR headers the season is written as a letter and a two digit year e.g. S23, W25.
+
+type SeasonType byte
+
+const (
+	Summer SeasonType = 'S'
+	Winter SeasonType = 'W'
+)
+
+type Season struct {
+	Type SeasonType
+	Year int // calendar year in which the season starts e.g. 2025 for W25
+}
+
+// Week is a single Sunday to Saturday week of a season
+type Week struct {
+	Number int // 1-based week number within the season
+	Start  time.Time
+	End    time.Time
+}
+
+// ParseSeason parses a season header token like "S23" or "W25"
+func ParseSeason(s string) (Season, error) {
+	if len(s) != 3 || (s[0] != byte(Summer) && s[0] != byte(Winter)) {
+		return Season{}, fmt.Errorf("ssimparser: invalid season, expected S or W followed by two digit year but have %v", s)
+	}
+	// Atoi alone would accept a sign e.g. S+1
+	if !isDigit(s[1]) || !isDigit(s[2]) {
+		return Season{}, fmt.Errorf("ssimparser: invalid season year in %v", s)
+	}
+	yy, _ := strconv.Atoi(s[1:])
+	return Season{Type: SeasonType(s[0]), Year: 2000 + yy}, nil
+}
+
+// SeasonOf returns the season the given date belongs to
+func SeasonOf(t time.Time) Season {
+	date := truncateToDate(t)
+	year := date.Year()
+	switch {
+	case date.Before(lastSunday(year, time.March)):
+		return Season{Type: Winter, Year: year - 1}
+	case date.Before(lastSunday(year, time.October)):
+		return Season{Type: Summer, Year: year}
+	default:
+		return Season{Type: Winter, Year: year}
+	}
+}
+
+// IsZero reports whether the season has not been set
+func (s Season) IsZero() bool {
+	return s.Type == 0 && s.Year == 0
+}
+
+// String returns the header form of the season e.g. "W25"
+func (s Season) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%c%02d", s.Type, s.Year%100)
+}
+
+// Start returns the first day (a Sunday) of the season
+func (s Season) Start() time.Time {
+	if s.Type == Winter {
+		return lastSunday(s.Year, time.October)
+	}
+	return lastSunday(s.Year, time.March)
+}
+
+// End returns the last day (a Saturday) of the season
+func (s Season) End() time.Time {
+	return s.Next().Start().AddDate(0, 0, -1)
+}
+
+// Contains reports whether the date of t falls within the season
+func (s Season) Contains(t time.Time) bool {
+	date := truncateToDate(t)
+	return !date.Before(s.Start()) && !date.After(s.End())
+}
+
+// WeekCount returns the number of weeks in the season
+func (s Season) WeekCount() int {
+	return (DaysBetween(s.End(), s.Start()) + 1) / 7
+}
+
+// Weeks lists the weeks of the season in order
+func (s Season) Weeks() []Week {
+	weeks := make([]Week, 0, s.WeekCount())
+	start := s.Start()
+	for i := 0; i < s.WeekCount(); i++ {
+		weekStart := start.AddDate(0, 0, 7*i)
+		weeks = append(weeks, Week{
+			Number: i + 1,
+			Start:  weekStart,
+			End:    weekStart.AddDate(0, 0, 6),
+		})
+	}
+	return weeks
+}
+
+// Next returns the season that follows s
+func (s Season) Next() Season {
+	if s.Type == Winter {
+		return Season{Type: Summer, Year: s.Year + 1}
+	}
+	return Season{Type: Winter, Year: s.Year}
+}
+
+// Previous returns the season that precedes s
+func (s Season) Previous() Season {
+	if s.Type == Winter {
+		return Season{Type: Summer, Year: s.Year}
+	}
+	return Season{Type: Winter, Year: s.Year - 1}
+}
+
+// yearOfMonth returns the calendar year in which the given month falls for the season.
+// Winter seasons start in the autumn of the season year and end in the spring of the
+// following one (W25: OCT25 - MAR26). A zero season resolves to the reference year.
+func (s Season) yearOfMonth(month int) int {
+	if s.IsZero() {
+		return referenceYear
+	}
+	if s.Type == Winter && month <= 6 {
+		return s.Year + 1
+	}
+	return s.Year
+}
+
+// ParseDateDDMMMYY parses a date with year as used in SSIM e.g. 14NOV26
+func ParseDateDDMMMYY(s string) (time.Time, error) {
+	if len(s) != 7 || !isDateDDMMM(s[:5]) {
+		return time.Time{}, fmt.Errorf("ssimparser: invalid date, expected DDMMMYY but have %v", s)
+	}
+	yy, err := strconv.Atoi(s[5:])
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("ssimparser: invalid year in DDMMMYY, expected integer but have %v", s[5:]))
+	}
+	return convertDDMMMtoDate(s[:5], 2000+yy)
+}
+
+// lastSunday returns the last Sunday of the given month
+func lastSunday(year int, month time.Month) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	return last.AddDate(0, 0, -int(last.Weekday()))
+}
+
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,109 @@
+package ssimparser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSeason(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Season
+		ok   bool
+	}{
+		{"S23", Season{Type: Summer, Year: 2023}, true},
+		{"W25", Season{Type: Winter, Year: 2025}, true},
+		{"W00", Season{Type: Winter, Year: 2000}, true},
+		{"S+1", Season{}, false},
+		{"S-1", Season{}, false},
+		{"S 1", Season{}, false},
+		{"X23", Season{}, false},
+		{"S2", Season{}, false},
+		{"S234", Season{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSeason(tt.in)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Errorf("ParseSeason(%q) = %v, %v; want %v, ok %v", tt.in, got, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSeasonBoundaries(t *testing.T) {
+	tests := []struct {
+		season     string
+		start, end string
+		weeks      int
+		next, prev string
+	}{
+		{"S25", "30MAR25", "25OCT25", 30, "W25", "W24"},
+		{"W25", "26OCT25", "28MAR26", 22, "S26", "S25"},
+		{"S24", "31MAR24", "26OCT24", 30, "W24", "W23"},
+		{"W24", "27OCT24", "29MAR25", 22, "S25", "S24"},
+		{"S00", "26MAR00", "28OCT00", 31, "W00", "W99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.season, func(t *testing.T) {
+			s, err := ParseSeason(tt.season)
+			if err != nil {
+				t.Fatalf("ParseSeason: %v", err)
+			}
+			if got := formatDDMMMYY(s.Start()); got != tt.start {
+				t.Errorf("Start() = %v, want %v", got, tt.start)
+			}
+			if got := formatDDMMMYY(s.End()); got != tt.end {
+				t.Errorf("End() = %v, want %v", got, tt.end)
+			}
+			if s.Start().Weekday() != time.Sunday || s.End().Weekday() != time.Saturday {
+				t.Errorf("season runs %v to %v, want Sunday to Saturday", s.Start().Weekday(), s.End().Weekday())
+			}
+			if got := s.WeekCount(); got != tt.weeks {
+				t.Errorf("WeekCount() = %d, want %d", got, tt.weeks)
+			}
+			weeks := s.Weeks()
+			if len(weeks) != tt.weeks || !weeks[0].Start.Equal(s.Start()) || !weeks[len(weeks)-1].End.Equal(s.End()) ||
+				weeks[len(weeks)-1].Number != tt.weeks {
+				t.Errorf("Weeks() = %d weeks from %v to %v", len(weeks), weeks[0].Start, weeks[len(weeks)-1].End)
+			}
+			if got := s.Next().String(); got != tt.next {
+				t.Errorf("Next() = %v, want %v", got, tt.next)
+			}
+			if got := s.Previous().String(); got != tt.prev {
+				t.Errorf("Previous() = %v, want %v", got, tt.prev)
+			}
+			if !s.Next().Start().Equal(s.End().AddDate(0, 0, 1)) {
+				t.Errorf("Next() starts %v, want the day after %v", s.Next().Start(), s.End())
+			}
+		})
+	}
+}
+
+func TestSeasonOfAndContains(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"29MAR25", "W24"},
+		{"30MAR25", "S25"},
+		{"25OCT25", "S25"},
+		{"26OCT25", "W25"},
+		{"31DEC25", "W25"},
+		{"01JAN26", "W25"},
+		{"28MAR26", "W25"},
+		{"29MAR26", "S26"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			d := date(t, tt.date)
+			got := SeasonOf(d.Add(23 * time.Hour))
+			if got.String() != tt.want {
+				t.Fatalf("SeasonOf(%v) = %v, want %v", tt.date, got, tt.want)
+			}
+			if !got.Contains(d) || got.Previous().Contains(d) || got.Next().Contains(d) {
+				t.Errorf("%v Contains(%v) is not exclusive to the season", got, tt.date)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package ssimparser
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// Series is a set of operating dates written the SSIM way
+//
+//	01APR28OCT 1234567     daily
+//	01APR28OCT 1030500/2   Monday, Wednesday and Friday every second week
+//
+// Set operations work on the concrete dates and return the result as
+// maximal series, see SeriesFromDates.
+type Series struct {
+	Period        *PeriodOfOperation
+	Days          DaysOfWeek
+	FrequencyRate int // 0 or 1 for weekly
+}
+
+// Series returns the period, days and frequency rate of the slot item
+func (s SlotItem) Series() Series {
+	return Series{Period: s.PeriodOfOperation, Days: s.DaysOfOperation, FrequencyRate: s.FrequencyRate}
+}
+
+// String returns the series as written on a data line e.g. 01APR28OCT 1234567 or 01APR28OCT 1030500/2
+func (s Series) String() string {
+	if s.Period == nil {
+		return s.Days.String()
+	}
+	str := fmt.Sprintf("%s%s %s", s.Period.EffectiveDate, s.Period.TerminationDate, s.Days)
+	if s.FrequencyRate > 1 {
+		str += fmt.Sprintf("/%d", s.FrequencyRate)
+	}
+	return str
+}
+
+// Dates enumerates the operating dates of the series
+func (s Series) Dates() []time.Time {
+	return operatingDates(s.Period, s.Days, s.FrequencyRate, 0)
+}
+
+// IsEmpty reports whether the series has no operating date
+func (s Series) IsEmpty() bool {
+	return len(s.Dates()) == 0
+}
+
+// Intersect returns the dates operated by both s and other
+func (s Series) Intersect(other Series) []Series {
+	in := dateSet(other.Dates())
+	dates := make([]time.Time, 0)
+	for _, date := range s.Dates() {
+		if in[date] {
+			dates = append(dates, date)
+		}
+	}
+	return SeriesFromDates(dates)
+}
+
+// Subtract returns the dates of s that other does not operate
+func (s Series) Subtract(other Series) []Series {
+	out := dateSet(other.Dates())
+	dates := make([]time.Time, 0)
+	for _, date := range s.Dates() {
+		if !out[date] {
+			dates = append(dates, date)
+		}
+	}
+	return SeriesFromDates(dates)
+}
+
+// Union returns the dates operated by s or other
+func (s Series) Union(other Series) []Series {
+	return SeriesFromDates(append(s.Dates(), other.Dates()...))
+}
+
+// SeriesFromDates describes a set of dates as series, each as long as possible.
+// Weeks with the same day pattern are merged, and a pattern change in the middle of
+// a week ends one series and starts the next on that day:
+//
+//	01APR-14APR daily, 15APR-28APR Mon/Wed/Fri -> 01APR14APR 1234567, 15APR28APR 1030500
+//
+// Single weeks repeating every n-th week are joined into one series with frequency rate n:
+//
+//	Mon/Wed/Fri of every second week from 01APR -> 01APR28OCT 1030500/2
+//
+// Periods are trimmed to the first and last operating date. Duplicates are ignored.
+// Weekly series have FrequencyRate 0, the value a data line without rate is parsed to,
+// so they compare equal to SlotItem.Series of the same line.
+func SeriesFromDates(dates []time.Time) []Series {
+	set := dateSet(dates)
+	if len(set) == 0 {
+		return nil
+	}
+	sorted := make([]time.Time, 0, len(set))
+	for date := range set {
+		sorted = append(sorted, date)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	series := make([]Series, 0)
+	var run *seriesRun
+	closeRun := func() {
+		if run != nil {
+			series = append(series, run.series())
+			run = nil
+		}
+	}
+	for week := weekStart(sorted[0]); !week.After(sorted[len(sorted)-1]); week = week.AddDate(0, 0, 7) {
+		var pattern DaysOfWeek
+		for i := 0; i < 7; i++ {
+			if set[week.AddDate(0, 0, i)] {
+				pattern |= 1 << i
+			}
+		}
+		if pattern == NoDays {
+			closeRun()
+			continue
+		}
+		from := 0
+		if run != nil {
+			mismatch := (pattern ^ run.days) & run.known
+			if mismatch == NoDays {
+				// days before the first operating date were unknown and are taken from this week
+				run.days, run.known = pattern, AllDays
+				run.last = week.AddDate(0, 0, lastDay(pattern))
+				continue
+			}
+			// the run continues up to the first differing day of the week
+			from = bits.TrailingZeros8(uint8(mismatch))
+			before := DaysOfWeek(1<<from - 1)
+			if prefix := pattern & before; prefix != NoDays {
+				run.days = run.days&^before | prefix
+				run.last = week.AddDate(0, 0, lastDay(prefix))
+			}
+			closeRun()
+		}
+		rest := pattern &^ DaysOfWeek(1<<from-1)
+		if rest == NoDays {
+			continue
+		}
+		first := bits.TrailingZeros8(uint8(rest))
+		run = &seriesRun{
+			days:  rest,
+			known: AllDays &^ DaysOfWeek(1<<first-1),
+			first: week.AddDate(0, 0, first),
+			last:  week.AddDate(0, 0, lastDay(rest)),
+		}
+	}
+	closeRun()
+	return joinFrequencies(series)
+}
+
+// compactSeries writes dates as a single series with the given frequency rate when that is exact,
+// otherwise as maximal weekly series
+func compactSeries(dates []time.Time, rate int) []Series {
+	if len(dates) == 0 {
+		return nil
+	}
+	set := dateSet(dates)
+	first, last := time.Time{}, time.Time{}
+	var days DaysOfWeek
+	for date := range set {
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if date.After(last) {
+			last = date
+		}
+		days |= DayOf(date.Weekday())
+	}
+	series := Series{Period: NewPeriodOfOperation(first, last), Days: days, FrequencyRate: rate}
+	if coversExactly(series, set) {
+		return []Series{series}
+	}
+	return SeriesFromDates(dates)
+}
+
+// joinFrequencies merges runs of single-week series spaced a constant n >= 2 weeks apart
+// into one series with frequency rate n, as long as it covers exactly the same dates
+func joinFrequencies(series []Series) []Series {
+	joined := make([]Series, 0, len(series))
+	for i := 0; i < len(series); {
+		count, best := 1, series[i]
+		if rate := weeksApart(series, i); rate >= 2 && withinWeek(series[i]) {
+			dates := dateSet(series[i].Dates())
+			days := series[i].Days
+			for j := i + 1; j < len(series) && withinWeek(series[j]) && weeksApart(series, j-1) == rate; j++ {
+				for _, date := range series[j].Dates() {
+					dates[date] = true
+				}
+				days |= series[j].Days
+				candidate := Series{Period: NewPeriodOfOperation(series[i].Period.Effective, series[j].Period.Termination), Days: days, FrequencyRate: rate}
+				if !coversExactly(candidate, dates) {
+					break
+				}
+				count, best = j-i+1, candidate
+			}
+		}
+		joined = append(joined, best)
+		i += count
+	}
+	return joined
+}
+
+// weeksApart returns how many weeks series i+1 starts after series i, 0 for the last one
+func weeksApart(series []Series, i int) int {
+	if i+1 >= len(series) {
+		return 0
+	}
+	return DaysBetween(weekStart(series[i+1].Period.Effective), weekStart(series[i].Period.Effective)) / 7
+}
+
+func withinWeek(s Series) bool {
+	return weekStart(s.Period.Effective).Equal(weekStart(s.Period.Termination))
+}
+
+// coversExactly reports whether s operates on every date of set and on no other
+func coversExactly(s Series, set map[time.Time]bool) bool {
+	dates := s.Dates()
+	if len(dates) != len(set) {
+		return false
+	}
+	for _, date := range dates {
+		if !set[date] {
+			return false
+		}
+	}
+	return true
+}
+
+// shift moves the series the given number of days forward, keeping its frequency rate
+func (s Series) shift(days int) Series {
+	if s.Period == nil {
+		return s
+	}
+	var shifted DaysOfWeek
+	for i := 0; i < 7; i++ {
+		if s.Days&(1<<i) != 0 {
+			shifted |= 1 << ((i + days%7 + 7) % 7)
+		}
+	}
+	period := NewPeriodOfOperation(s.Period.Effective.AddDate(0, 0, days), s.Period.Termination.AddDate(0, 0, days))
+	return Series{Period: period, Days: shifted, FrequencyRate: s.FrequencyRate}
+}
+
+// seriesRun is a weekly series being built by SeriesFromDates.
+// known holds the weekdays already fixed by an operating date inside the period,
+// the others are still free to take the pattern of the next week.
+type seriesRun struct {
+	days        DaysOfWeek
+	known       DaysOfWeek
+	first, last time.Time
+}
+
+func (r *seriesRun) series() Series {
+	days := r.days
+	if DaysBetween(r.last, r.first) < 7 {
+		// a period shorter than a week only needs the days it actually contains
+		days = NoDays
+		for date := r.first; !date.After(r.last); date = date.AddDate(0, 0, 1) {
+			if r.days.Operates(date) {
+				days |= DayOf(date.Weekday())
+			}
+		}
+	}
+	return Series{Period: NewPeriodOfOperation(r.first, r.last), Days: days}
+}
+
+// lastDay returns the index (0 Monday - 6 Sunday) of the last day in d
+func lastDay(d DaysOfWeek) int {
+	return bits.Len8(uint8(d)) - 1
+}
+
+// dateSet normalises dates to calendar days
+func dateSet(dates []time.Time) map[time.Time]bool {
+	set := make(map[time.Time]bool, len(dates))
+	for _, date := range dates {
+		set[truncateToDate(date)] = true
+	}
+	return set
+}
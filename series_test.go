@@ -0,0 +1,121 @@
+package ssimparser
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := ParseDateDDMMMYY(s)
+	if err != nil {
+		t.Fatalf("ParseDateDDMMMYY(%q): %v", s, err)
+	}
+	return d
+}
+
+func series(t *testing.T, from, to, days string, rate int) Series {
+	t.Helper()
+	d, err := ParseDaysOfWeek(days)
+	if err != nil {
+		t.Fatalf("ParseDaysOfWeek(%q): %v", days, err)
+	}
+	return Series{Period: NewPeriodOfOperation(date(t, from), date(t, to)), Days: d, FrequencyRate: rate}
+}
+
+func seriesStrings(series []Series) string {
+	s := make([]string, 0, len(series))
+	for _, one := range series {
+		s = append(s, one.String())
+	}
+	return strings.Join(s, ", ")
+}
+
+func TestSeriesOperations(t *testing.T) {
+	fortnightly := series(t, "01APR25", "28OCT25", "1030500", 2)
+	tests := []struct {
+		name string
+		got  []Series
+		want string
+	}{
+		{"union with itself", fortnightly.Union(fortnightly), "02APR27OCT 1030500/2"},
+		{"intersect with daily", fortnightly.Intersect(series(t, "01APR25", "28OCT25", "1234567", 0)), "02APR27OCT 1030500/2"},
+		{"subtract Fridays", fortnightly.Subtract(series(t, "01APR25", "28OCT25", "0000500", 0)), "02APR27OCT 1030000/2"},
+		{
+			"pattern change",
+			series(t, "01APR25", "14APR25", "1234567", 0).Union(series(t, "15APR25", "28APR25", "1030500", 0)),
+			"01APR14APR 1234567, 16APR28APR 1030500",
+		},
+		{"every third week", SeriesFromDates([]time.Time{date(t, "07APR25"), date(t, "28APR25"), date(t, "19MAY25")}), "07APR19MAY 1000000/3"},
+		{"uneven spacing", SeriesFromDates([]time.Time{date(t, "07APR25"), date(t, "21APR25"), date(t, "12MAY25")}), "07APR21APR 1000000/2, 12MAY12MAY 1000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seriesStrings(tt.got); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeriesFromDatesWeeklyRate(t *testing.T) {
+	item := parseSCR(t, "SCR\nS25\n01MAR\nKRK\nN LO012 31MAR24OCT 1030500 189738 0930WAW J\n").Items[0]
+	got := SeriesFromDates(item.Series().Dates())
+	if len(got) != 1 || got[0].FrequencyRate != 0 || got[0].String() != item.Series().String() {
+		t.Fatalf("SeriesFromDates = %v, want %v with rate 0", seriesStrings(got), item.Series())
+	}
+	if got[0].FrequencyRate != item.FrequencyRate {
+		t.Errorf("FrequencyRate = %d, want the parsed rate %d", got[0].FrequencyRate, item.FrequencyRate)
+	}
+}
+
+// TestSeriesFromDatesExact checks that random date sets are described exactly
+func TestSeriesFromDatesExact(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	start := date(t, "30MAR25")
+	for run := 0; run < 500; run++ {
+		set := make(map[time.Time]bool)
+		for i := 0; i < 1+random.Intn(40); i++ {
+			set[start.AddDate(0, 0, random.Intn(120))] = true
+		}
+		if run%2 == 0 {
+			// a fortnightly block so the rate detection is exercised
+			for d := start.AddDate(0, 0, random.Intn(14)); d.Before(start.AddDate(0, 0, 200)); d = d.AddDate(0, 0, 14) {
+				set[d] = true
+			}
+		}
+		dates := make([]time.Time, 0, len(set))
+		for d := range set {
+			dates = append(dates, d)
+		}
+		covered := make(map[time.Time]bool)
+		for _, s := range SeriesFromDates(dates) {
+			for _, d := range s.Dates() {
+				if covered[d] || !set[d] {
+					t.Fatalf("run %d: %v covers %v twice or outside the set", run, s, formatDDMMMYY(d))
+				}
+				covered[d] = true
+			}
+		}
+		if len(covered) != len(set) {
+			t.Fatalf("run %d: %d of %d dates covered", run, len(covered), len(set))
+		}
+	}
+}
+
+func TestCompactSeries(t *testing.T) {
+	fortnightly := series(t, "07APR25", "20OCT25", "1000000", 2)
+	if got := compactSeries(fortnightly.Dates(), 2); seriesStrings(got) != fortnightly.String() {
+		t.Errorf("compactSeries keeping the rate = %v, want %v", seriesStrings(got), fortnightly)
+	}
+	daily := series(t, "01APR25", "10APR25", "1234567", 0)
+	dates := append(daily.Dates()[:3], daily.Dates()[5:]...)
+	if got, want := seriesStrings(compactSeries(dates, 0)), seriesStrings(SeriesFromDates(dates)); got != want {
+		t.Errorf("compactSeries with a gap = %v, want %v", got, want)
+	}
+	if got := compactSeries(nil, 0); got != nil {
+		t.Errorf("compactSeries(nil) = %v", got)
+	}
+}
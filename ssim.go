@@ -0,0 +1,463 @@
+package ssimparser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSIM Chapter 7 schedule data set: fixed-width records of 200 bytes
+//
+//	1  header       one per data set
+//	2  carrier      one per airline, followed by its legs
+//	3  flight leg   one per leg and itinerary variation
+//	4  segment data optional, follows the leg it belongs to
+//	5  trailer      closes the carrier, checks the serial number of the last record
+//
+// Records filled with zeros pad the data set to blocks and are skipped.
+// Every record ends with its serial number in positions 195-200.
+
+const ssimRecordLength = 200
+
+type SSIMFile struct {
+	Title         string // e.g. AIRLINE STANDARD SCHEDULE DATA SET
+	DataSetSerial string
+	Carriers      []*SSIMCarrier
+	RecordSerial  int
+}
+
+type SSIMCarrier struct {
+	TimeMode         string // U for UTC, L for local times
+	Airline          string
+	Season           Season
+	Validity         *PeriodOfOperation // period of schedule validity
+	CreationDate     time.Time
+	Title            string
+	ReleaseDate      time.Time
+	Status           string // P for planning, C for confirmed
+	CreatorReference string
+	GeneralInfo      string
+	CreationTime     string // HHMM
+	Legs             []*SSIMLeg
+	Trailer          *SSIMTrailer
+	RecordSerial     int
+}
+
+// SSIMLeg is a type 3 flight leg record with the segment data records following it
+type SSIMLeg struct {
+	Flight             FlightDesignator
+	ItineraryVariation string // IVI, together with the leg sequence it identifies the leg within the flight
+	LegSequence        int
+	ServiceType        ServiceType
+	PeriodOfOperation  *PeriodOfOperation
+	DaysOfOperation    DaysOfWeek
+	FrequencyRate      int // 0 for weekly
+	// Departure side
+	DepartureStation      string
+	PassengerSTD          string // HHMM
+	AircraftSTD           string // HHMM
+	DepartureUTCVariation int    // minutes local time is ahead of UTC
+	DepartureTerminal     string
+	// Arrival side
+	ArrivalStation      string
+	AircraftSTA         string // HHMM
+	PassengerSTA        string // HHMM
+	ArrivalUTCVariation int    // minutes local time is ahead of UTC
+	ArrivalTerminal     string
+	AircraftType        string // IATA aircraft type e.g. 73H
+	Configuration       string // aircraft configuration/version e.g. Y189
+	// OnwardFlight is the next flight operated by the aircraft, nil when not given
+	OnwardFlight *FlightDesignator
+	// RotationLayover is the number of days until the onward flight departs
+	RotationLayover int
+	// Date variations of the departure and arrival against the period dates, -1 for the day before
+	DepartureDateVariation int
+	ArrivalDateVariation   int
+	Segments               []*SSIMSegment
+	RecordSerial           int
+}
+
+// SSIMSegment is a type 4 segment data record
+type SSIMSegment struct {
+	BoardPointIndicator string
+	OffPointIndicator   string
+	DataElement         int // data element identifier (DEI)
+	BoardPoint          string
+	OffPoint            string
+	Data                string
+	RecordSerial        int
+}
+
+type SSIMTrailer struct {
+	Airline      string
+	ReleaseDate  time.Time
+	SerialCheck  int    // serial number of the record preceding the trailer
+	Continuation string // C when another carrier follows, E at the end of the data set
+	RecordSerial int
+}
+
+// Series returns the period, days and frequency rate of the leg
+func (l SSIMLeg) Series() Series {
+	return Series{Period: l.PeriodOfOperation, Days: l.DaysOfOperation, FrequencyRate: l.FrequencyRate}
+}
+
+// SSIMReader reads a Chapter 7 data set.
+// Records may be separated by line breaks or follow each other as plain 200 byte blocks,
+// lines shorter than 200 bytes are padded with spaces.
+type SSIMReader struct {
+	r io.Reader
+}
+
+func NewSSIMReader(r io.Reader) *SSIMReader {
+	return &SSIMReader{r: r}
+}
+
+// Read reads the complete data set.
+// Failures are returned as *ParserError with the record number in LineNumber, wrapping ErrBadRecord.
+func (sr *SSIMReader) Read() (*SSIMFile, error) {
+	data, err := io.ReadAll(sr.r)
+	if err != nil {
+		return nil, NewParserError("reading data set failed", 0, "", err, Critical)
+	}
+	file := &SSIMFile{Carriers: make([]*SSIMCarrier, 0)}
+	var (
+		carrier    *SSIMCarrier
+		leg        *SSIMLeg
+		lastSerial int
+	)
+	for i, record := range splitRecords(data) {
+		number := i + 1
+		if isPaddingRecord(record) {
+			continue
+		}
+		fail := func(format string, args ...any) error {
+			err := fmt.Errorf("%w: "+format, append([]any{ErrBadRecord}, args...)...)
+			return NewParserError(fmt.Sprintf("bad type %c record", record[0]), number, strings.TrimRight(record, " "), err, Critical)
+		}
+
+		serial, err := strconv.Atoi(ssimField(record, 195, 200))
+		if err != nil {
+			return nil, fail("invalid record serial number %q", record[194:200])
+		}
+		if serial <= lastSerial {
+			return nil, fail("record serial number %d does not follow %d", serial, lastSerial)
+		}
+
+		switch record[0] {
+		case '1':
+			if file.RecordSerial != 0 {
+				return nil, fail("second header record")
+			}
+			file.Title = ssimField(record, 2, 35)
+			file.DataSetSerial = ssimField(record, 192, 194)
+			file.RecordSerial = serial
+		case '2':
+			if carrier != nil && carrier.Trailer == nil {
+				return nil, fail("carrier %v is not closed by a trailer record", carrier.Airline)
+			}
+			carrier, err = parseCarrierRecord(record)
+			if err != nil {
+				return nil, fail("%w", err)
+			}
+			carrier.RecordSerial = serial
+			file.Carriers = append(file.Carriers, carrier)
+			leg = nil
+		case '3':
+			if carrier == nil || carrier.Trailer != nil {
+				return nil, fail("flight leg outside of a carrier")
+			}
+			leg, err = parseLegRecord(record, carrier)
+			if err != nil {
+				return nil, fail("%w", err)
+			}
+			leg.RecordSerial = serial
+			carrier.Legs = append(carrier.Legs, leg)
+		case '4':
+			if leg == nil {
+				return nil, fail("segment data without a flight leg")
+			}
+			segment, err := parseSegmentRecord(record, leg)
+			if err != nil {
+				return nil, fail("%w", err)
+			}
+			segment.RecordSerial = serial
+			leg.Segments = append(leg.Segments, segment)
+		case '5':
+			if carrier == nil || carrier.Trailer != nil {
+				return nil, fail("trailer without a carrier")
+			}
+			trailer, err := parseTrailerRecord(record)
+			if err != nil {
+				return nil, fail("%w", err)
+			}
+			if trailer.Airline != carrier.Airline {
+				return nil, fail("trailer airline %v does not match carrier %v", trailer.Airline, carrier.Airline)
+			}
+			if trailer.SerialCheck != lastSerial {
+				return nil, fail("serial number check %d does not match the last record %d", trailer.SerialCheck, lastSerial)
+			}
+			trailer.RecordSerial = serial
+			carrier.Trailer = trailer
+			leg = nil
+		default:
+			return nil, fail("unknown record type")
+		}
+		lastSerial = serial
+	}
+	if file.RecordSerial == 0 {
+		return nil, NewParserError("missing header record", 0, "", ErrBadRecord, Critical)
+	}
+	if carrier != nil && carrier.Trailer == nil {
+		return nil, NewParserError(fmt.Sprintf("carrier %v is not closed by a trailer record", carrier.Airline), 0, "", ErrBadRecord, Critical)
+	}
+	return file, nil
+}
+
+func parseCarrierRecord(record string) (*SSIMCarrier, error) {
+	carrier := &SSIMCarrier{
+		TimeMode:         ssimField(record, 2, 2),
+		Airline:          ssimField(record, 3, 5),
+		Title:            ssimField(record, 36, 64),
+		Status:           ssimField(record, 72, 72),
+		CreatorReference: ssimField(record, 73, 107),
+		GeneralInfo:      ssimField(record, 109, 169),
+		CreationTime:     ssimField(record, 191, 194),
+		Legs:             make([]*SSIMLeg, 0),
+	}
+	if carrier.Airline == "" {
+		return nil, fmt.Errorf("missing airline designator")
+	}
+	if season := ssimField(record, 11, 13); season != "" {
+		parsed, err := ParseSeason(season)
+		if err != nil {
+			return nil, err
+		}
+		carrier.Season = parsed
+	}
+	from, err := ParseDateDDMMMYY(ssimField(record, 15, 21))
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule validity from: %v", ErrBadPeriod, err)
+	}
+	to, err := parseOpenDate(ssimField(record, 22, 28), time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule validity to: %v", ErrBadPeriod, err)
+	}
+	carrier.Validity = NewPeriodOfOperation(from, to)
+	if date := ssimField(record, 29, 35); date != "" {
+		if carrier.CreationDate, err = ParseDateDDMMMYY(date); err != nil {
+			return nil, err
+		}
+	}
+	if date := ssimField(record, 65, 71); date != "" {
+		if carrier.ReleaseDate, err = ParseDateDDMMMYY(date); err != nil {
+			return nil, err
+		}
+	}
+	return carrier, nil
+}
+
+func parseLegRecord(record string, carrier *SSIMCarrier) (*SSIMLeg, error) {
+	flight, err := ssimFlight(ssimField(record, 3, 5), ssimField(record, 6, 9), ssimField(record, 2, 2))
+	if err != nil {
+		return nil, err
+	}
+	leg := &SSIMLeg{
+		Flight:             flight,
+		ItineraryVariation: ssimField(record, 10, 11),
+		ServiceType:        ServiceType(ssimField(record, 14, 14)),
+		DepartureStation:   ssimField(record, 37, 39),
+		PassengerSTD:       ssimField(record, 40, 43),
+		AircraftSTD:        ssimField(record, 44, 47),
+		DepartureTerminal:  ssimField(record, 53, 54),
+		ArrivalStation:     ssimField(record, 55, 57),
+		AircraftSTA:        ssimField(record, 58, 61),
+		PassengerSTA:       ssimField(record, 62, 65),
+		ArrivalTerminal:    ssimField(record, 71, 72),
+		AircraftType:       ssimField(record, 73, 75),
+		Configuration:      ssimField(record, 173, 192),
+		Segments:           make([]*SSIMSegment, 0),
+	}
+	if leg.LegSequence, err = strconv.Atoi(ssimField(record, 12, 13)); err != nil {
+		return nil, fmt.Errorf("invalid leg sequence number %q", record[11:13])
+	}
+
+	from, err := ParseDateDDMMMYY(ssimField(record, 15, 21))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadPeriod, err)
+	}
+	to, err := parseOpenDate(ssimField(record, 22, 28), carrier.Validity.Termination)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadPeriod, err)
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: %v is after %v", ErrBadPeriod, record[14:21], record[21:28])
+	}
+	leg.PeriodOfOperation = NewPeriodOfOperation(from, to)
+	if leg.DaysOfOperation, err = ParseDaysOfWeek(record[28:35]); err != nil {
+		return nil, err
+	}
+	if rate := ssimField(record, 36, 36); rate != "" {
+		if leg.FrequencyRate, err = parseFrequencyRate("/" + rate); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isStationCode(leg.DepartureStation) || !isStationCode(leg.ArrivalStation) {
+		return nil, fmt.Errorf("%w: invalid leg %v-%v", ErrBadStation, leg.DepartureStation, leg.ArrivalStation)
+	}
+	for _, t := range []string{leg.PassengerSTD, leg.AircraftSTD, leg.AircraftSTA, leg.PassengerSTA} {
+		if !isTimeHHMM(t) {
+			return nil, fmt.Errorf("invalid time %q", t)
+		}
+	}
+	if leg.DepartureUTCVariation, err = parseUTCVariation(ssimField(record, 48, 52)); err != nil {
+		return nil, err
+	}
+	if leg.ArrivalUTCVariation, err = parseUTCVariation(ssimField(record, 66, 70)); err != nil {
+		return nil, err
+	}
+
+	if airline := ssimField(record, 138, 140); airline != "" {
+		onward, err := ssimFlight(airline, ssimField(record, 141, 144), ssimField(record, 146, 146))
+		if err != nil {
+			return nil, fmt.Errorf("onward flight: %v", err)
+		}
+		leg.OnwardFlight = &onward
+		if layover := ssimField(record, 145, 145); layover != "" {
+			if leg.RotationLayover, err = strconv.Atoi(layover); err != nil {
+				return nil, fmt.Errorf("invalid aircraft rotation layover %q", layover)
+			}
+		}
+	}
+
+	if leg.DepartureDateVariation, err = parseDateVariation(record[192]); err != nil {
+		return nil, err
+	}
+	if leg.ArrivalDateVariation, err = parseDateVariation(record[193]); err != nil {
+		return nil, err
+	}
+	return leg, nil
+}
+
+func parseSegmentRecord(record string, leg *SSIMLeg) (*SSIMSegment, error) {
+	flight, err := ssimFlight(ssimField(record, 3, 5), ssimField(record, 6, 9), ssimField(record, 2, 2))
+	if err != nil {
+		return nil, err
+	}
+	if !flight.Equal(leg.Flight) || flight.Suffix != leg.Flight.Suffix || ssimField(record, 10, 11) != leg.ItineraryVariation {
+		return nil, fmt.Errorf("segment of %v does not belong to leg %v", flight, leg.Flight)
+	}
+	segment := &SSIMSegment{
+		BoardPointIndicator: ssimField(record, 29, 29),
+		OffPointIndicator:   ssimField(record, 30, 30),
+		BoardPoint:          ssimField(record, 34, 36),
+		OffPoint:            ssimField(record, 37, 39),
+		Data:                ssimField(record, 40, 194),
+	}
+	if segment.DataElement, err = strconv.Atoi(ssimField(record, 31, 33)); err != nil {
+		return nil, fmt.Errorf("invalid data element identifier %q", record[30:33])
+	}
+	return segment, nil
+}
+
+func parseTrailerRecord(record string) (*SSIMTrailer, error) {
+	trailer := &SSIMTrailer{
+		Airline:      ssimField(record, 3, 5),
+		Continuation: ssimField(record, 194, 194),
+	}
+	var err error
+	if date := ssimField(record, 6, 12); date != "" {
+		if trailer.ReleaseDate, err = ParseDateDDMMMYY(date); err != nil {
+			return nil, err
+		}
+	}
+	if trailer.SerialCheck, err = strconv.Atoi(ssimField(record, 188, 193)); err != nil {
+		return nil, fmt.Errorf("invalid serial number check reference %q", record[187:193])
+	}
+	return trailer, nil
+}
+
+// splitRecords returns the 200 byte records of data
+func splitRecords(data []byte) []string {
+	records := make([]string, 0)
+	if bytes.ContainsAny(data, "\r\n") {
+		for _, line := range strings.FieldsFunc(string(data), func(r rune) bool { return r == '\n' || r == '\r' }) {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			records = append(records, padRecord(line))
+		}
+		return records
+	}
+	for start := 0; start < len(data); start += ssimRecordLength {
+		end := min(start+ssimRecordLength, len(data))
+		records = append(records, padRecord(string(data[start:end])))
+	}
+	return records
+}
+
+func padRecord(s string) string {
+	if len(s) >= ssimRecordLength {
+		return s[:ssimRecordLength]
+	}
+	return s + strings.Repeat(" ", ssimRecordLength-len(s))
+}
+
+func isPaddingRecord(record string) bool {
+	return strings.Trim(record, "0") == ""
+}
+
+// ssimField returns positions from to (1-based, inclusive) without surrounding spaces
+func ssimField(record string, from, to int) string {
+	return strings.TrimSpace(record[from-1 : to])
+}
+
+// ssimFlight builds a designator from the fixed-width airline, flight number and suffix fields
+func ssimFlight(airline, number, suffix string) (FlightDesignator, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil {
+		return FlightDesignator{}, fmt.Errorf("%w: invalid flight number %q", ErrBadFlightDesignator, number)
+	}
+	return ParseFlightDesignator(fmt.Sprintf("%s%d%s", airline, n, suffix))
+}
+
+// parseOpenDate parses DDMMMYY, 00XXX00 stands for an open end and returns open
+func parseOpenDate(s string, open time.Time) (time.Time, error) {
+	if s == "00XXX00" {
+		if open.IsZero() {
+			return time.Time{}, fmt.Errorf("open date 00XXX00 without schedule validity")
+		}
+		return open, nil
+	}
+	return ParseDateDDMMMYY(s)
+}
+
+// parseUTCVariation reads +HHMM or -HHMM into minutes
+func parseUTCVariation(s string) (int, error) {
+	if len(s) != 5 || (s[0] != '+' && s[0] != '-') || !isTimeHHMM(s[1:]) {
+		return 0, fmt.Errorf("invalid UTC/local time variation %q", s)
+	}
+	hh, _ := strconv.Atoi(s[1:3])
+	mm, _ := strconv.Atoi(s[3:5])
+	minutes := hh*60 + mm
+	if s[0] == '-' {
+		minutes = -minutes
+	}
+	return minutes, nil
+}
+
+// parseDateVariation reads a date variation: blank or 0 for the same day, 1-9 days later, A for the day before
+func parseDateVariation(b byte) (int, error) {
+	switch {
+	case b == ' ':
+		return 0, nil
+	case isDigit(b):
+		return int(b - '0'), nil
+	case b == 'A':
+		return -1, nil
+	}
+	return 0, fmt.Errorf("invalid date variation %q", b)
+}
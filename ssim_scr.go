@@ -0,0 +1,237 @@
+package ssimparser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SCRFromSSIM cuts the legs of a Chapter 7 carrier into one initial SCR (N lines) per coordinated airport,
+// returned in the order of airports. messageDate goes into the SCR header, e.g. the carrier creation date.
+//
+// Times are converted to UTC and the period and days follow the UTC date at the airport.
+// An arrival that continues on the next leg of its itinerary (a multi-leg flight, e.g. LO1 WAW-KRK-GDN
+// at KRK) or whose onward flight departs again from the same airport with the same equipment
+// is written as a turnaround line, the departure carrying the overnight indicator when the
+// aircraft stays for one or more nights. Dates where only one side operates are written as
+// separate arrival and departure lines.
+func SCRFromSSIM(carrier *SSIMCarrier, messageDate time.Time, airports ...string) []*SCRMessage {
+	season := carrier.Season
+	if season.IsZero() && carrier.Validity != nil {
+		season = SeasonOf(carrier.Validity.Effective)
+	}
+	itineraries := legItineraries(carrier.Legs)
+
+	messages := make([]*SCRMessage, 0, len(airports))
+	for _, airport := range airports {
+		message := &SCRMessage{
+			Identifier:          "SCR",
+			Season:              season,
+			MessageDate:         formatDDMMM(messageDate),
+			AirportCode:         airport,
+			Originator:          OriginatorAirline,
+			AdministrativeLines: make([]string, 0),
+			Items:               make([]*SlotItem, 0),
+		}
+		movements := airportMovements(carrier, itineraries, airport)
+		for _, m := range movements {
+			message.Items = append(message.Items, m.items(airport)...)
+		}
+		for _, item := range message.Items {
+			item.SlotKey = item.GetSlotKey()
+		}
+		message.Changes = LinkChanges(message.Items)
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// movement is one leg seen from a coordinated airport
+type movement struct {
+	leg       *SSIMLeg
+	direction Direction
+	time      string             // UTC HHMM
+	shift     int                // days from the period date to the UTC date, date variation included
+	dates     map[time.Time]bool // UTC dates at the airport not yet written as a turnaround
+	station   string             // origin for arrivals, destination for departures
+	adjacent  string             // previous or next station
+	// turnarounds are the departures the arrival was paired with
+	turnarounds []turnaround
+}
+
+type turnaround struct {
+	departure *movement
+	overnight int
+	dates     []time.Time // arrival dates
+}
+
+// airportMovements returns the arrivals and departures at airport in leg order
+// with arrivals paired to the departures of the same aircraft rotation
+func airportMovements(carrier *SSIMCarrier, itineraries map[*SSIMLeg][2]string, airport string) []*movement {
+	movements := make([]*movement, 0)
+	for _, leg := range carrier.Legs {
+		route := itineraries[leg]
+		if leg.ArrivalStation == airport {
+			m := &movement{leg: leg, direction: DirectionArrival, station: route[0], adjacent: leg.DepartureStation}
+			m.resolve(carrier, leg.AircraftSTA, leg.ArrivalDateVariation, leg.ArrivalUTCVariation)
+			movements = append(movements, m)
+		}
+		if leg.DepartureStation == airport {
+			m := &movement{leg: leg, direction: DirectionDeparture, station: route[1], adjacent: leg.ArrivalStation}
+			m.resolve(carrier, leg.AircraftSTD, leg.DepartureDateVariation, leg.DepartureUTCVariation)
+			movements = append(movements, m)
+		}
+	}
+
+	for _, arrival := range movements {
+		if arrival.direction != DirectionArrival {
+			continue
+		}
+		for _, departure := range movements {
+			if departure.direction != DirectionDeparture {
+				continue
+			}
+			overnight, ok := rotation(arrival, departure)
+			if !ok || overnight < 0 {
+				continue
+			}
+			paired := make([]time.Time, 0)
+			for date := range arrival.dates {
+				if departure.dates[date.AddDate(0, 0, overnight)] {
+					paired = append(paired, date)
+				}
+			}
+			if len(paired) == 0 {
+				continue
+			}
+			for _, date := range paired {
+				delete(arrival.dates, date)
+				delete(departure.dates, date.AddDate(0, 0, overnight))
+			}
+			arrival.turnarounds = append(arrival.turnarounds, turnaround{departure: departure, overnight: overnight, dates: paired})
+		}
+	}
+	return movements
+}
+
+// rotation reports whether the aircraft of arrival leaves again on departure and the number of days
+// between the UTC dates of both. The departure is either the next leg of the same itinerary or the
+// onward flight of the arrival, in both cases with the same equipment.
+func rotation(arrival, departure *movement) (int, bool) {
+	a, d := arrival.leg, departure.leg
+	if d.AircraftType != a.AircraftType || d.Configuration != a.Configuration {
+		return 0, false
+	}
+	if d.Flight.Equal(a.Flight) && d.Flight.Suffix == a.Flight.Suffix &&
+		d.ItineraryVariation == a.ItineraryVariation && d.LegSequence == a.LegSequence+1 {
+		// both legs share the flight date, the shifts already hold the date variations
+		return departure.shift - arrival.shift, true
+	}
+	if a.OnwardFlight != nil && d.Flight.Equal(*a.OnwardFlight) && d.Flight.Suffix == a.OnwardFlight.Suffix {
+		// the layover counts days between the local dates, so only the UTC part of both shifts is added
+		return a.RotationLayover + (departure.shift - d.DepartureDateVariation) - (arrival.shift - a.ArrivalDateVariation), true
+	}
+	return 0, false
+}
+
+// resolve fills the UTC time, the date shift and the UTC dates at the airport
+func (m *movement) resolve(carrier *SSIMCarrier, localTime string, dateVariation, utcVariation int) {
+	if carrier.TimeMode != "L" {
+		utcVariation = 0
+	}
+	hh, _ := strconv.Atoi(localTime[:2])
+	mm, _ := strconv.Atoi(localTime[2:])
+	minutes := hh*60 + mm - utcVariation
+	m.time = fmt.Sprintf("%02d%02d", (minutes+24*60)%(24*60)/60, (minutes+24*60)%60)
+	m.shift = dateVariation
+	switch {
+	case minutes < 0:
+		m.shift--
+	case minutes >= 24*60:
+		m.shift++
+	}
+	m.dates = make(map[time.Time]bool)
+	for _, date := range m.leg.Series().Dates() {
+		m.dates[date.AddDate(0, 0, m.shift)] = true
+	}
+}
+
+// items writes the turnaround lines of an arrival followed by the dates left for the movement alone
+func (m *movement) items(airport string) []*SlotItem {
+	items := make([]*SlotItem, 0)
+	for _, t := range m.turnarounds {
+		for _, series := range compactSeries(t.dates, m.leg.FrequencyRate) {
+			arrival := m.item(airport, series)
+			departure := t.departure.item(airport, series)
+			arrival.Turnaround, departure.Turnaround = true, true
+			departure.DayChangeIndicator = t.overnight
+			items = append(items, arrival, departure)
+		}
+	}
+	dates := make([]time.Time, 0, len(m.dates))
+	for date := range m.dates {
+		dates = append(dates, date)
+	}
+	for _, series := range compactSeries(dates, m.leg.FrequencyRate) {
+		items = append(items, m.item(airport, series))
+	}
+	return items
+}
+
+func (m *movement) item(airport string, series Series) *SlotItem {
+	return &SlotItem{
+		ActionCode:        ActionNewSlot,
+		Flight:            m.leg.Flight,
+		PeriodOfOperation: series.Period,
+		DaysOfOperation:   series.Days,
+		FrequencyRate:     series.FrequencyRate,
+		AircraftType:      m.leg.AircraftType,
+		Configuration:     slotSeats(m.leg.Configuration),
+		ServiceType:       m.leg.ServiceType,
+		Direction:         m.direction,
+		ClearanceAirport:  airport,
+		ScheduledTime:     m.time,
+		Station:           m.station,
+		AdjacentStation:   m.adjacent,
+	}
+}
+
+// legItineraries maps every leg to the origin and destination of its itinerary
+// (flight, suffix and itinerary variation, ordered by leg sequence)
+func legItineraries(legs []*SSIMLeg) map[*SSIMLeg][2]string {
+	groups := make(map[string][]*SSIMLeg)
+	for _, leg := range legs {
+		key := leg.Flight.Airline + leg.Flight.PaddedNumber() + "/" + leg.ItineraryVariation
+		groups[key] = append(groups[key], leg)
+	}
+	routes := make(map[*SSIMLeg][2]string, len(legs))
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].LegSequence < group[j].LegSequence })
+		route := [2]string{group[0].DepartureStation, group[len(group)-1].ArrivalStation}
+		for _, leg := range group {
+			routes[leg] = route
+		}
+	}
+	return routes
+}
+
+// slotSeats writes the seats of an aircraft configuration as the 3-digit SCR seat count,
+// a configuration of more than 999 seats is written as 999
+func slotSeats(configuration string) string {
+	return fmt.Sprintf("%03d", min(configurationSeats(configuration), 999))
+}
+
+// configurationSeats adds up the seats of an aircraft configuration e.g. C30Y220 -> 250
+func configurationSeats(configuration string) int {
+	seats, number := 0, 0
+	for i := 0; i < len(configuration); i++ {
+		if isDigit(configuration[i]) {
+			number = number*10 + int(configuration[i]-'0')
+			continue
+		}
+		seats += number
+		number = 0
+	}
+	return seats + number
+}
@@ -0,0 +1,131 @@
+package ssimparser
+
+import (
+	"strings"
+	"testing"
+)
+
+// ssimLeg is a UTC leg of a 738 with 189 seats
+func ssimLeg(t *testing.T, flight, from, to, days, departure, std, arrival, sta string) *SSIMLeg {
+	t.Helper()
+	designator, err := ParseFlightDesignator(flight)
+	if err != nil {
+		t.Fatalf("ParseFlightDesignator(%q): %v", flight, err)
+	}
+	s := series(t, from, to, days, 0)
+	return &SSIMLeg{
+		Flight:             designator,
+		ItineraryVariation: "01",
+		LegSequence:        1,
+		ServiceType:        "J",
+		PeriodOfOperation:  s.Period,
+		DaysOfOperation:    s.Days,
+		DepartureStation:   departure,
+		PassengerSTD:       std,
+		AircraftSTD:        std,
+		ArrivalStation:     arrival,
+		AircraftSTA:        sta,
+		PassengerSTA:       sta,
+		AircraftType:       "738",
+		Configuration:      "Y189",
+	}
+}
+
+func TestSCRFromSSIM(t *testing.T) {
+	krk := ssimLeg(t, "LO11", "30MAR25", "25OCT25", "1234567", "WAW", "0700", "KRK", "0750")
+	krk.OnwardFlight, krk.RotationLayover = &FlightDesignator{Airline: "LO", Number: "12"}, 0
+	back := ssimLeg(t, "LO12", "30MAR25", "25OCT25", "1234500", "KRK", "0840", "WAW", "0930")
+	gdn := ssimLeg(t, "LO21", "30MAR25", "25OCT25", "1000000", "WAW", "1200", "GDN", "1250")
+	carrier := &SSIMCarrier{TimeMode: "U", Airline: "LO", Legs: []*SSIMLeg{krk, back, gdn}}
+	carrier.Season, _ = ParseSeason("S25")
+
+	messages := SCRFromSSIM(carrier, date(t, "01MAR25"), "KRK", "GDN")
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	tests := []struct {
+		message *SCRMessage
+		want    []string
+	}{
+		{messages[0], []string{
+			"NLO11 LO12 31MAR24OCT 1234500 189738 WAW0750 0840WAW JJ",
+			"NLO11 30MAR25OCT 0000067 189738 WAW0750 J",
+		}},
+		{messages[1], []string{"NLO21 31MAR20OCT 1000000 189738 WAW1250 J"}},
+	}
+	for _, tt := range tests {
+		if tt.message.MessageDate != "01MAR" || tt.message.Originator != OriginatorAirline {
+			t.Errorf("%v: date %q, originator %v", tt.message.AirportCode, tt.message.MessageDate, tt.message.Originator)
+		}
+		got := encodedDataLines(t, tt.message)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%v: got\n%s\nwant\n%s", tt.message.AirportCode, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestSCRFromSSIMLargeConfiguration(t *testing.T) {
+	leg := ssimLeg(t, "EK1", "30MAR25", "25OCT25", "1234567", "DXB", "0230", "LHR", "0640")
+	leg.AircraftType, leg.Configuration = "388", "F14J76Y427W500"
+	carrier := &SSIMCarrier{TimeMode: "U", Airline: "EK", Legs: []*SSIMLeg{leg}}
+	carrier.Season, _ = ParseSeason("S25")
+
+	want := "NEK1 30MAR25OCT 1234567 999388 DXB0640 J"
+	got := encodedDataLines(t, SCRFromSSIM(carrier, date(t, "01MAR25"), "LHR")[0])
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSCRFromSSIMOvernightWithDateVariation(t *testing.T) {
+	// leaves JFK on Sunday evening and lands in Warsaw on Monday
+	arrival := ssimLeg(t, "LO15", "30MAR25", "25OCT25", "0000007", "JFK", "2310", "WAW", "0035")
+	arrival.ArrivalDateVariation = 1
+	tests := []struct {
+		name    string
+		layover int
+		days    string
+		want    string
+	}{
+		{"same day", 0, "1000000", "NLO15 LO16 31MAR20OCT 1000000 189738 JFK0035 1000JFK JJ"},
+		{"one night", 1, "0200000", "NLO15 LO16 31MAR20OCT 1000000 189738 JFK0035 10001JFK JJ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leg := *arrival
+			leg.OnwardFlight, leg.RotationLayover = &FlightDesignator{Airline: "LO", Number: "16"}, tt.layover
+			departure := ssimLeg(t, "LO16", "30MAR25", "25OCT25", tt.days, "WAW", "1000", "JFK", "1830")
+			carrier := &SSIMCarrier{TimeMode: "U", Airline: "LO", Legs: []*SSIMLeg{&leg, departure}}
+			carrier.Season, _ = ParseSeason("S25")
+
+			message := SCRFromSSIM(carrier, date(t, "01MAR25"), "WAW")[0]
+			if got := encodedDataLines(t, message); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSCRFromSSIMMultiLegFlight(t *testing.T) {
+	// LO1 WAW-KRK-GDN, the second leg leaves Krakow after midnight
+	first := ssimLeg(t, "LO1", "30MAR25", "25OCT25", "1000000", "WAW", "2200", "KRK", "2250")
+	second := ssimLeg(t, "LO1", "30MAR25", "25OCT25", "1000000", "KRK", "0010", "GDN", "0100")
+	second.LegSequence, second.DepartureDateVariation, second.ArrivalDateVariation = 2, 1, 1
+	carrier := &SSIMCarrier{TimeMode: "U", Airline: "LO", Legs: []*SSIMLeg{first, second}}
+	carrier.Season, _ = ParseSeason("S25")
+
+	messages := SCRFromSSIM(carrier, date(t, "01MAR25"), "KRK", "GDN")
+	tests := []struct {
+		message *SCRMessage
+		want    []string
+	}{
+		{messages[0], []string{"NLO1 LO1 31MAR20OCT 1000000 189738 WAW2250 00101GDN JJ"}},
+		{messages[1], []string{"NLO1 01APR21OCT 0200000 189738 WAWKRK0100 J"}},
+	}
+	for _, tt := range tests {
+		got := encodedDataLines(t, tt.message)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%v: got\n%s\nwant\n%s", tt.message.AirportCode, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
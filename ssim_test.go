@@ -0,0 +1,96 @@
+package ssimparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// ssimRecord writes the fields at their 1-based positions into a blank record ending with serial
+func ssimRecord(serial int, fields ...any) string {
+	record := []byte(strings.Repeat(" ", ssimRecordLength))
+	for i := 0; i+1 < len(fields); i += 2 {
+		copy(record[fields[i].(int)-1:], fields[i+1].(string))
+	}
+	copy(record[194:], fmt.Sprintf("%06d", serial))
+	return string(record)
+}
+
+// ssimDataSet is a header, one LO carrier with a single leg and its segment data, and the trailer.
+// Two padding records follow the header.
+func ssimDataSet(legSerial, trailerCheck int) string {
+	padding := strings.Repeat("0", ssimRecordLength)
+	return strings.Join([]string{
+		ssimRecord(1, 1, "1", 2, "AIRLINE STANDARD SCHEDULE DATA SET", 192, "001"),
+		padding,
+		padding,
+		ssimRecord(2, 1, "2", 2, "U", 3, "LO", 11, "S25", 15, "30MAR25", 22, "25OCT25", 29, "01MAR25"),
+		ssimRecord(legSerial, 1, "3", 3, "LO", 6, "0014", 10, "01", 12, "01", 14, "J", 15, "07APR25", 22, "00XXX00",
+			29, "1000000", 36, "2", 37, "WAW", 40, "09300930", 48, "+0200", 55, "CDG", 58, "11451145", 66, "+0200",
+			73, "73H", 138, "LO 0015", 145, "1", 173, "Y189"),
+		ssimRecord(legSerial+1, 1, "4", 3, "LO", 6, "0014", 10, "01", 29, "AB", 31, "010", 34, "WAWCDG", 40, "AF1234"),
+		ssimRecord(legSerial+2, 1, "5", 3, "LO", 188, fmt.Sprintf("%06d", trailerCheck), 194, "E"),
+	}, "\n") + "\n"
+}
+
+func TestSSIMReader(t *testing.T) {
+	file, err := NewSSIMReader(strings.NewReader(ssimDataSet(3, 4))).Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if file.Title != "AIRLINE STANDARD SCHEDULE DATA SET" || len(file.Carriers) != 1 {
+		t.Fatalf("file = %+v", file)
+	}
+	carrier := file.Carriers[0]
+	if carrier.Airline != "LO" || carrier.Season.String() != "S25" || carrier.Trailer == nil || carrier.Trailer.Continuation != "E" {
+		t.Errorf("carrier = %+v", carrier)
+	}
+	if len(carrier.Legs) != 1 {
+		t.Fatalf("got %d legs, want 1", len(carrier.Legs))
+	}
+	leg := carrier.Legs[0]
+	if got := leg.Series().String(); got != "07APR25OCT 1000000/2" {
+		t.Errorf("series = %v, the open end should take the schedule validity", got)
+	}
+	if leg.DepartureStation != "WAW" || leg.AircraftSTD != "0930" || leg.ArrivalStation != "CDG" || leg.AircraftSTA != "1145" ||
+		leg.DepartureUTCVariation != 120 || leg.AircraftType != "73H" || leg.Configuration != "Y189" {
+		t.Errorf("leg = %+v", leg)
+	}
+	if leg.OnwardFlight == nil || leg.OnwardFlight.String() != "LO15" || leg.RotationLayover != 1 {
+		t.Errorf("onward flight = %v, layover %d", leg.OnwardFlight, leg.RotationLayover)
+	}
+	if len(leg.Segments) != 1 || leg.Segments[0].DataElement != 10 || leg.Segments[0].Data != "AF1234" {
+		t.Errorf("segments = %+v", leg.Segments)
+	}
+
+	// the same records as plain 200 byte blocks
+	plain := strings.ReplaceAll(ssimDataSet(3, 4), "\n", "")
+	if _, err := NewSSIMReader(strings.NewReader(plain)).Read(); err != nil {
+		t.Errorf("Read without line breaks: %v", err)
+	}
+}
+
+func TestSSIMReaderRejectsBadRecords(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		record int
+	}{
+		{"serial out of order", ssimDataSet(2, 3), 5},
+		{"wrong trailer check", ssimDataSet(3, 3), 7},
+		{"missing header", strings.Join(strings.Split(ssimDataSet(3, 4), "\n")[1:], "\n"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSSIMReader(strings.NewReader(tt.data)).Read()
+			if !errors.Is(err, ErrBadRecord) {
+				t.Fatalf("Read error = %v, want %v", err, ErrBadRecord)
+			}
+			var perr *ParserError
+			if !errors.As(err, &perr) || perr.LineNumber != tt.record {
+				t.Errorf("Read error = %v, want record %d", err, tt.record)
+			}
+		})
+	}
+}
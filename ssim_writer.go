@@ -0,0 +1,473 @@
+package ssimparser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSIMWriter writes a Chapter 7 data set.
+// Records are separated by line breaks and written in blocks of five: the header and every
+// carrier record are followed by four zero records, and zero records after the trailer
+// complete the last block of a carrier. Zero records do not take a serial number.
+// Serial numbers and the trailer check are computed while writing, the RecordSerial fields
+// of file are not used.
+type SSIMWriter struct {
+	w io.Writer
+}
+
+func NewSSIMWriter(w io.Writer) *SSIMWriter {
+	return &SSIMWriter{w: w}
+}
+
+// Write writes the header, carrier, leg, segment and trailer records of file
+func (sw *SSIMWriter) Write(file *SSIMFile) error {
+	if file == nil {
+		return errors.New("ssimparser: cannot write nil SSIM file")
+	}
+	bw := bufio.NewWriter(sw.w)
+	serial, records := 0, 0
+	write := func(record []byte) {
+		serial++
+		records++
+		copy(record[194:], fmt.Sprintf("%06d", serial))
+		bw.Write(record)
+		bw.WriteString("\n")
+	}
+	// pad completes the current block of five records
+	pad := func() {
+		for ; records%5 != 0; records++ {
+			bw.WriteString(strings.Repeat("0", ssimRecordLength))
+			bw.WriteString("\n")
+		}
+	}
+
+	title := file.Title
+	if title == "" {
+		title = "AIRLINE STANDARD SCHEDULE DATA SET"
+	}
+	header := newRecord('1')
+	putField(header, 2, 35, title)
+	putField(header, 192, 194, file.DataSetSerial)
+	write(header)
+	pad()
+
+	for i, carrier := range file.Carriers {
+		record, err := carrierRecord(carrier)
+		if err != nil {
+			return err
+		}
+		write(record)
+		pad()
+		for _, leg := range carrier.Legs {
+			record, err := legRecord(leg)
+			if err != nil {
+				return err
+			}
+			write(record)
+			for _, segment := range leg.Segments {
+				write(segmentRecord(leg, segment))
+			}
+		}
+		trailer := newRecord('5')
+		putField(trailer, 3, 5, carrier.Airline)
+		if carrier.Trailer != nil && !carrier.Trailer.ReleaseDate.IsZero() {
+			putField(trailer, 6, 12, formatDDMMMYY(carrier.Trailer.ReleaseDate))
+		}
+		putField(trailer, 188, 193, fmt.Sprintf("%06d", serial))
+		continuation := "C"
+		if i == len(file.Carriers)-1 {
+			continuation = "E"
+		}
+		putField(trailer, 194, 194, continuation)
+		write(trailer)
+		pad()
+	}
+	return bw.Flush()
+}
+
+func carrierRecord(carrier *SSIMCarrier) ([]byte, error) {
+	if carrier.Validity == nil {
+		return nil, fmt.Errorf("ssimparser: carrier %v has no schedule validity", carrier.Airline)
+	}
+	timeMode := carrier.TimeMode
+	if timeMode == "" {
+		timeMode = "U"
+	}
+	record := newRecord('2')
+	putField(record, 2, 2, timeMode)
+	putField(record, 3, 5, carrier.Airline)
+	if !carrier.Season.IsZero() {
+		putField(record, 11, 13, carrier.Season.String())
+	}
+	putField(record, 15, 21, formatDDMMMYY(carrier.Validity.Effective))
+	putField(record, 22, 28, formatDDMMMYY(carrier.Validity.Termination))
+	if !carrier.CreationDate.IsZero() {
+		putField(record, 29, 35, formatDDMMMYY(carrier.CreationDate))
+	}
+	putField(record, 36, 64, carrier.Title)
+	if !carrier.ReleaseDate.IsZero() {
+		putField(record, 65, 71, formatDDMMMYY(carrier.ReleaseDate))
+	}
+	putField(record, 72, 72, carrier.Status)
+	putField(record, 73, 107, carrier.CreatorReference)
+	putField(record, 109, 169, carrier.GeneralInfo)
+	putField(record, 191, 194, carrier.CreationTime)
+	return record, nil
+}
+
+func legRecord(leg *SSIMLeg) ([]byte, error) {
+	if leg.PeriodOfOperation == nil {
+		return nil, fmt.Errorf("ssimparser: leg %s has no period of operation", leg.Flight)
+	}
+	number, err := strconv.Atoi(leg.Flight.Number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadFlightDesignator, leg.Flight)
+	}
+	if leg.LegSequence < 0 || leg.LegSequence > 99 || len(leg.ItineraryVariation) > 2 {
+		return nil, fmt.Errorf("%w: leg %s has itinerary variation %q and leg sequence %d, both take two digits", ErrBadRecord, leg.Flight, leg.ItineraryVariation, leg.LegSequence)
+	}
+	record := newRecord('3')
+	putFlight(record, leg.Flight, number, leg.ItineraryVariation, leg.LegSequence, leg.ServiceType)
+	putField(record, 15, 21, formatDDMMMYY(leg.PeriodOfOperation.Effective))
+	putField(record, 22, 28, formatDDMMMYY(leg.PeriodOfOperation.Termination))
+	putField(record, 29, 35, strings.ReplaceAll(leg.DaysOfOperation.String(), "0", " "))
+	if leg.FrequencyRate > 1 {
+		putField(record, 36, 36, strconv.Itoa(leg.FrequencyRate))
+	}
+	putField(record, 37, 39, leg.DepartureStation)
+	putField(record, 40, 43, leg.PassengerSTD)
+	putField(record, 44, 47, leg.AircraftSTD)
+	putField(record, 48, 52, formatUTCVariation(leg.DepartureUTCVariation))
+	putField(record, 53, 54, leg.DepartureTerminal)
+	putField(record, 55, 57, leg.ArrivalStation)
+	putField(record, 58, 61, leg.AircraftSTA)
+	putField(record, 62, 65, leg.PassengerSTA)
+	putField(record, 66, 70, formatUTCVariation(leg.ArrivalUTCVariation))
+	putField(record, 71, 72, leg.ArrivalTerminal)
+	putField(record, 73, 75, leg.AircraftType)
+	if onward := leg.OnwardFlight; onward != nil {
+		onwardNumber, err := strconv.Atoi(onward.Number)
+		if err != nil {
+			return nil, fmt.Errorf("%w: onward flight %v", ErrBadFlightDesignator, onward)
+		}
+		putField(record, 138, 140, onward.Airline)
+		putField(record, 141, 144, fmt.Sprintf("%04d", onwardNumber))
+		putField(record, 145, 145, strconv.Itoa(leg.RotationLayover))
+		putField(record, 146, 146, onward.Suffix)
+	}
+	putField(record, 173, 192, leg.Configuration)
+	record[192] = formatDateVariation(leg.DepartureDateVariation)
+	record[193] = formatDateVariation(leg.ArrivalDateVariation)
+	return record, nil
+}
+
+func segmentRecord(leg *SSIMLeg, segment *SSIMSegment) []byte {
+	number, _ := strconv.Atoi(leg.Flight.Number)
+	record := newRecord('4')
+	putFlight(record, leg.Flight, number, leg.ItineraryVariation, leg.LegSequence, leg.ServiceType)
+	putField(record, 29, 29, segment.BoardPointIndicator)
+	putField(record, 30, 30, segment.OffPointIndicator)
+	putField(record, 31, 33, fmt.Sprintf("%03d", segment.DataElement))
+	putField(record, 34, 36, segment.BoardPoint)
+	putField(record, 37, 39, segment.OffPoint)
+	putField(record, 40, 194, segment.Data)
+	return record
+}
+
+// putFlight writes the flight identification shared by leg and segment records (positions 2-14)
+func putFlight(record []byte, flight FlightDesignator, number int, ivi string, sequence int, service ServiceType) {
+	putField(record, 2, 2, flight.Suffix)
+	putField(record, 3, 5, flight.Airline)
+	putField(record, 6, 9, fmt.Sprintf("%04d", number))
+	putField(record, 10, 11, ivi)
+	putField(record, 12, 13, fmt.Sprintf("%02d", sequence))
+	putField(record, 14, 14, string(service))
+}
+
+func newRecord(recordType byte) []byte {
+	record := []byte(strings.Repeat(" ", ssimRecordLength))
+	record[0] = recordType
+	return record
+}
+
+// putField writes value left justified into positions from to (1-based, inclusive), cutting it to fit
+func putField(record []byte, from, to int, value string) {
+	if len(value) > to-from+1 {
+		value = value[:to-from+1]
+	}
+	copy(record[from-1:to], value)
+}
+
+func formatUTCVariation(minutes int) string {
+	sign := '+'
+	if minutes < 0 {
+		sign, minutes = '-', -minutes
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, minutes/60, minutes%60)
+}
+
+func formatDateVariation(days int) byte {
+	if days < 0 {
+		return 'A'
+	}
+	return byte('0' + days)
+}
+
+// SSIMFromSlots builds a Chapter 7 data set from slot items, one carrier per airline with UTC times.
+// A departure and the arrival of the same flight at its next station are merged into one leg
+// on the dates both operate. The arrival half of a turnaround names the departure as onward flight.
+//
+// A movement known at one airport only, e.g. the items of a single airport SCR or SAL, is written
+// as a leg whose other end is computed with blockTime(departure station, arrival station).
+// The block times are not part of the slots, SSIMFromSlots fails without a blockTime function.
+//
+// Dates are the operating dates of the items, so overnight turnaround departures leave on the
+// day they are indicated for. The arrival of a merged leg lands on the day that brings the time
+// between both movements closest to the block time, a sector of more than 24 hours therefore
+// needs a blockTime function that knows it.
+func SSIMFromSlots(items []*SlotItem, season Season, blockTime func(from, to string) time.Duration) (*SSIMFile, error) {
+	if blockTime == nil {
+		return nil, errors.New("ssimparser: cannot build legs from slots without block times")
+	}
+	file := &SSIMFile{Title: "AIRLINE STANDARD SCHEDULE DATA SET", DataSetSerial: "001", Carriers: make([]*SSIMCarrier, 0)}
+
+	onward := make(map[*SlotItem]*SlotItem)
+	for i := 0; i+1 < len(items); i++ {
+		if items[i].Turnaround && items[i+1].Turnaround && items[i].Direction == DirectionArrival {
+			onward[items[i]] = items[i+1]
+			i++
+		}
+	}
+
+	byAirline := make(map[string][]*SlotItem)
+	airlines := make([]string, 0)
+	for _, item := range items {
+		if !holdsSlot(item.ActionCode) || item.PeriodOfOperation == nil || !isTimeHHMM(item.ScheduledTime) {
+			continue
+		}
+		if _, ok := byAirline[item.Flight.Airline]; !ok {
+			airlines = append(airlines, item.Flight.Airline)
+		}
+		byAirline[item.Flight.Airline] = append(byAirline[item.Flight.Airline], item)
+	}
+	sort.Strings(airlines)
+
+	for _, airline := range airlines {
+		legs, err := slotLegs(byAirline[airline], onward, blockTime)
+		if err != nil {
+			return nil, err
+		}
+		carrier := &SSIMCarrier{TimeMode: "U", Airline: airline, Season: season, Status: "C", Legs: legs}
+		for _, leg := range legs {
+			from, to := leg.PeriodOfOperation.Effective, leg.PeriodOfOperation.Termination
+			if carrier.Validity != nil {
+				if carrier.Validity.Effective.Before(from) {
+					from = carrier.Validity.Effective
+				}
+				if carrier.Validity.Termination.After(to) {
+					to = carrier.Validity.Termination
+				}
+			}
+			carrier.Validity = NewPeriodOfOperation(from, to)
+		}
+		if carrier.Validity == nil && !season.IsZero() {
+			carrier.Validity = NewPeriodOfOperation(season.Start(), season.End())
+		}
+		if carrier.Season.IsZero() && carrier.Validity != nil {
+			carrier.Season = SeasonOf(carrier.Validity.Effective)
+		}
+		file.Carriers = append(file.Carriers, carrier)
+	}
+	return file, nil
+}
+
+// slotSide is a departure or arrival item with the dates not yet written as a leg
+type slotSide struct {
+	item  *SlotItem
+	dates map[time.Time]bool
+}
+
+// slotLegs merges the departures and arrivals of one airline into legs,
+// the dates where only one side operates become legs of their own.
+// A flight written as more than 99 legs does not fit the itinerary variation and fails.
+func slotLegs(items []*SlotItem, onward map[*SlotItem]*SlotItem, blockTime func(from, to string) time.Duration) ([]*SSIMLeg, error) {
+	sides := make([]*slotSide, 0, len(items))
+	for _, item := range items {
+		sides = append(sides, &slotSide{item: item, dates: dateSet(item.OperatingDates())})
+	}
+
+	legs := make([]*SSIMLeg, 0)
+	for _, departure := range sides {
+		if departure.item.Direction != DirectionDeparture {
+			continue
+		}
+		d := departure.item
+		for _, arrival := range sides {
+			a := arrival.item
+			if a.Direction != DirectionArrival || !a.Flight.Equal(d.Flight) || a.Flight.Suffix != d.Flight.Suffix ||
+				a.ClearanceAirport != adjacentStation(d) || adjacentStation(a) != d.ClearanceAirport {
+				continue
+			}
+			variation := arrivalVariation(d.ScheduledTime, a.ScheduledTime, blockTime(d.ClearanceAirport, a.ClearanceAirport))
+			paired := make([]time.Time, 0)
+			for date := range departure.dates {
+				if arrival.dates[date.AddDate(0, 0, variation)] {
+					paired = append(paired, date)
+				}
+			}
+			for _, date := range paired {
+				delete(departure.dates, date)
+				delete(arrival.dates, date.AddDate(0, 0, variation))
+			}
+			for _, series := range compactSeries(paired, d.FrequencyRate) {
+				leg := newSlotLeg(d, series, d.ClearanceAirport, a.ClearanceAirport)
+				leg.AircraftSTD, leg.PassengerSTD = d.ScheduledTime, d.ScheduledTime
+				leg.AircraftSTA, leg.PassengerSTA = a.ScheduledTime, a.ScheduledTime
+				leg.ArrivalDateVariation = variation
+				setOnwardFlight(leg, onward[a])
+				legs = append(legs, leg)
+			}
+		}
+	}
+
+	for _, side := range sides {
+		item := side.item
+		dates := make([]time.Time, 0, len(side.dates))
+		var leg func(Series) *SSIMLeg
+		if item.Direction == DirectionDeparture {
+			to := adjacentStation(item)
+			arrival := clockMinutes(item.ScheduledTime) + int(blockTime(item.ClearanceAirport, to)/time.Minute)
+			for date := range side.dates {
+				dates = append(dates, date)
+			}
+			leg = func(series Series) *SSIMLeg {
+				leg := newSlotLeg(item, series, item.ClearanceAirport, to)
+				leg.AircraftSTD, leg.PassengerSTD = item.ScheduledTime, item.ScheduledTime
+				leg.AircraftSTA, leg.PassengerSTA = formatClock(arrival), formatClock(arrival)
+				leg.ArrivalDateVariation = dayOffset(arrival)
+				return leg
+			}
+		} else {
+			from := adjacentStation(item)
+			departure := clockMinutes(item.ScheduledTime) - int(blockTime(from, item.ClearanceAirport)/time.Minute)
+			// the period holds the departure dates
+			for date := range side.dates {
+				dates = append(dates, date.AddDate(0, 0, dayOffset(departure)))
+			}
+			leg = func(series Series) *SSIMLeg {
+				leg := newSlotLeg(item, series, from, item.ClearanceAirport)
+				leg.AircraftSTD, leg.PassengerSTD = formatClock(departure), formatClock(departure)
+				leg.AircraftSTA, leg.PassengerSTA = item.ScheduledTime, item.ScheduledTime
+				leg.ArrivalDateVariation = -dayOffset(departure)
+				setOnwardFlight(leg, onward[item])
+				return leg
+			}
+		}
+		for _, series := range compactSeries(dates, item.FrequencyRate) {
+			legs = append(legs, leg(series))
+		}
+	}
+
+	sort.SliceStable(legs, func(i, j int) bool {
+		if legs[i].Flight.PaddedNumber() != legs[j].Flight.PaddedNumber() {
+			return legs[i].Flight.PaddedNumber() < legs[j].Flight.PaddedNumber()
+		}
+		return legs[i].PeriodOfOperation.Effective.Before(legs[j].PeriodOfOperation.Effective)
+	})
+	// every leg is its own single leg itinerary, numbered per flight
+	variations := make(map[string]int)
+	for _, leg := range legs {
+		key := leg.Flight.String()
+		variations[key]++
+		if variations[key] > 99 {
+			return nil, fmt.Errorf("%w: flight %v needs more than 99 itinerary variations", ErrBadRecord, leg.Flight)
+		}
+		leg.ItineraryVariation = fmt.Sprintf("%02d", variations[key])
+	}
+	return legs, nil
+}
+
+// arrivalVariation returns the days between departure and arrival that bring the time
+// between both closest to block, the arrival always being after the departure
+func arrivalVariation(departure, arrival string, block time.Duration) int {
+	elapsed := clockMinutes(arrival) - clockMinutes(departure)
+	variation := 0
+	if elapsed <= 0 {
+		variation, elapsed = 1, elapsed+24*60
+	}
+	target := int(block / time.Minute)
+	for elapsed+12*60 < target {
+		variation, elapsed = variation+1, elapsed+24*60
+	}
+	return variation
+}
+
+// adjacentStation returns the previous station of an arrival or the next station of a departure
+func adjacentStation(s *SlotItem) string {
+	if s.AdjacentStation != "" {
+		return s.AdjacentStation
+	}
+	return s.Station
+}
+
+// clockMinutes returns the minutes since midnight of HHMM
+func clockMinutes(hhmm string) int {
+	hh, _ := strconv.Atoi(hhmm[:2])
+	mm, _ := strconv.Atoi(hhmm[2:])
+	return hh*60 + mm
+}
+
+// formatClock writes minutes counted from midnight of any day as HHMM
+func formatClock(minutes int) string {
+	minutes = (minutes%(24*60) + 24*60) % (24 * 60)
+	return fmt.Sprintf("%02d%02d", minutes/60, minutes%60)
+}
+
+// dayOffset returns the days minutes counted from midnight lie before or after that day
+func dayOffset(minutes int) int {
+	if minutes < 0 {
+		return -((-minutes + 24*60 - 1) / (24 * 60))
+	}
+	return minutes / (24 * 60)
+}
+
+// newSlotLeg fills a leg of item from to operated on series, the caller sets the times
+func newSlotLeg(item *SlotItem, series Series, from, to string) *SSIMLeg {
+	return &SSIMLeg{
+		Flight:            item.Flight,
+		LegSequence:       1,
+		ServiceType:       item.ServiceType,
+		PeriodOfOperation: series.Period,
+		DaysOfOperation:   series.Days,
+		FrequencyRate:     series.FrequencyRate,
+		DepartureStation:  from,
+		ArrivalStation:    to,
+		AircraftType:      item.AircraftType,
+		Configuration:     slotConfiguration(item.Configuration),
+		Segments:          make([]*SSIMSegment, 0),
+	}
+}
+
+func setOnwardFlight(leg *SSIMLeg, departure *SlotItem) {
+	if departure == nil {
+		return
+	}
+	flight := departure.Flight
+	leg.OnwardFlight = &flight
+	leg.RotationLayover = departure.DayChangeIndicator
+}
+
+// slotConfiguration writes the SCR seat count as an all economy configuration e.g. 189 -> Y189
+func slotConfiguration(seats string) string {
+	n, err := strconv.Atoi(seats)
+	if err != nil || n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Y%d", n)
+}
@@ -0,0 +1,138 @@
+package ssimparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAndRead writes file as a Chapter 7 data set and reads it back
+func writeAndRead(t *testing.T, file *SSIMFile) *SSIMFile {
+	t.Helper()
+	var sb strings.Builder
+	if err := NewSSIMWriter(&sb).Write(file); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	records := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(records)%5 != 0 {
+		t.Errorf("%d records, want whole blocks of five", len(records))
+	}
+	read, err := NewSSIMReader(strings.NewReader(sb.String())).Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return read
+}
+
+// legString writes the parts of a leg the tests compare e.g. LO12 26OCT28MAR 1234567 KRK0930 WAW1020+0
+func legString(leg *SSIMLeg) string {
+	s := leg.Flight.String() + " " + leg.Series().String() + " " + leg.DepartureStation + leg.AircraftSTD + " " +
+		leg.ArrivalStation + leg.AircraftSTA + "+" + string(formatDateVariation(leg.ArrivalDateVariation))
+	if leg.OnwardFlight != nil {
+		s += " " + leg.OnwardFlight.String()
+	}
+	return s
+}
+
+func TestSSIMFromSlots(t *testing.T) {
+	krk := parseSCR(t, "SCR\nW25\n20OCT\nKRK\nN LO012 26OCT28MAR 1234567 189738 0930WAW J\nN LO014 26OCT28MAR 1234567 189738 2330WAW J\n")
+	waw := parseSCR(t, "SCR\nW25\n20OCT\nWAW\nNLO012 26OCT28MAR 1234567 189738 KRK1020 J\n")
+	blockTime := func(from, to string) time.Duration { return 50 * time.Minute }
+
+	file, err := SSIMFromSlots(append(krk.Items, waw.Items...), krk.Season, blockTime)
+	if err != nil {
+		t.Fatalf("SSIMFromSlots: %v", err)
+	}
+	if len(file.Carriers) != 1 {
+		t.Fatalf("got %d carriers, want 1", len(file.Carriers))
+	}
+	want := []string{
+		"LO12 26OCT28MAR 1234567 KRK0930 WAW1020+0",
+		"LO14 26OCT28MAR 1234567 KRK2330 WAW0020+1",
+	}
+	read := writeAndRead(t, file)
+	for i, leg := range read.Carriers[0].Legs {
+		if i >= len(want) || legString(leg) != want[i] {
+			t.Errorf("leg %d = %s, want %v", i, legString(leg), want)
+		}
+	}
+	if len(read.Carriers[0].Legs) != len(want) {
+		t.Errorf("got %d legs, want %d", len(read.Carriers[0].Legs), len(want))
+	}
+}
+
+func TestSSIMFromSingleAirportSCR(t *testing.T) {
+	// the aircraft comes in from Warsaw after midnight and leaves for Warsaw the next day
+	krk := parseSCR(t, "SCR\nW25\n20OCT\nKRK\nNLO011 LO012 26OCT28MAR 1000000 189738 WAW0030 07001WAW JJ\n")
+
+	if _, err := SSIMFromSlots(krk.Items, krk.Season, nil); err == nil {
+		t.Fatal("SSIMFromSlots without block times: got nil error")
+	}
+	file, err := SSIMFromSlots(krk.Items, krk.Season, func(from, to string) time.Duration { return time.Hour })
+	if err != nil {
+		t.Fatalf("SSIMFromSlots: %v", err)
+	}
+	want := []string{
+		"LO11 26OCT22MAR 0000007 WAW2330 KRK0030+1 LO12",
+		"LO12 28OCT24MAR 0200000 KRK0700 WAW0800+0",
+	}
+	read := writeAndRead(t, file)
+	if len(read.Carriers) != 1 || len(read.Carriers[0].Legs) != len(want) {
+		t.Fatalf("got %+v, want one carrier with %d legs", read.Carriers, len(want))
+	}
+	for i, leg := range read.Carriers[0].Legs {
+		if legString(leg) != want[i] {
+			t.Errorf("leg %d = %s, want %s", i, legString(leg), want[i])
+		}
+	}
+	if layover := read.Carriers[0].Legs[0].RotationLayover; layover != 1 {
+		t.Errorf("rotation layover = %d, want 1", layover)
+	}
+}
+
+func TestArrivalVariation(t *testing.T) {
+	tests := []struct {
+		departure, arrival string
+		block              time.Duration
+		want               int
+	}{
+		{"0930", "1020", time.Hour, 0},
+		{"2330", "0020", time.Hour, 1},
+		{"2200", "2300", time.Hour, 0},
+		// an ultra long haul sector lands on the next day at a later clock time
+		{"2200", "2300", 25 * time.Hour, 1},
+		{"0100", "0200", 49 * time.Hour, 2},
+	}
+	for _, tt := range tests {
+		if got := arrivalVariation(tt.departure, tt.arrival, tt.block); got != tt.want {
+			t.Errorf("arrivalVariation(%s, %s, %v) = %d, want %d", tt.departure, tt.arrival, tt.block, got, tt.want)
+		}
+	}
+}
+
+func TestSSIMTwoDigitFields(t *testing.T) {
+	leg := ssimLeg(t, "LO12", "26OCT25", "28MAR26", "1234567", "KRK", "0930", "WAW", "1020")
+	leg.LegSequence = 100
+	file := &SSIMFile{Carriers: []*SSIMCarrier{{Airline: "LO", Validity: leg.PeriodOfOperation, Legs: []*SSIMLeg{leg}}}}
+	if err := NewSSIMWriter(io.Discard).Write(file); !errors.Is(err, ErrBadRecord) {
+		t.Errorf("leg sequence 100: got %v, want ErrBadRecord", err)
+	}
+
+	// a departure at every minute from 0000 to 0139 makes 100 itineraries of LO012
+	var sb strings.Builder
+	sb.WriteString("SCR\nW25\n20OCT\nKRK\n")
+	for minute := 0; minute < 100; minute++ {
+		fmt.Fprintf(&sb, "N LO012 26OCT28MAR 1234567 189738 %sWAW J\n", formatClock(minute))
+	}
+	krk := parseSCR(t, sb.String())
+	blockTime := func(from, to string) time.Duration { return time.Hour }
+	if _, err := SSIMFromSlots(krk.Items, krk.Season, blockTime); !errors.Is(err, ErrBadRecord) {
+		t.Errorf("100 itineraries: got %v, want ErrBadRecord", err)
+	}
+	if _, err := SSIMFromSlots(krk.Items[:99], krk.Season, blockTime); err != nil {
+		t.Errorf("99 itineraries: %v", err)
+	}
+}
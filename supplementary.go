@@ -0,0 +1,125 @@
+package ssimparser
+
+import (
+	"slices"
+	"strings"
+)
+
+// SupplementaryData holds the "/ ..." elements that may follow the service type on a data line
+//
+//	KAB457 26MAR28OCT 0204060 189738 KIX0500 J / CR.KRK000123/ RE.SUBJECT TO APRON CAPACITY/
+//
+// Each element ends with "/". Elements of the form <tag>.<value> with a known tag are decoded:
+//
+//	CR.KRK000123   coordinator reference
+//	PS.NRT         previous station
+//	NS.HKG         next station
+//	RE.FREE TEXT   remark, may be repeated
+//
+// Every other element (and a repeated CR, PS or NS) is kept raw in the order written.
+// String writes the decoded elements first, in the order above, followed by the raw ones.
+type SupplementaryData struct {
+	CoordinatorReference string
+	PreviousStation      string
+	NextStation          string
+	Remarks              []string
+	Unknown              []string // elements that are not decoded, as written
+}
+
+const (
+	supplementaryCoordinatorReference = "CR"
+	supplementaryPreviousStation      = "PS"
+	supplementaryNextStation          = "NS"
+	supplementaryRemark               = "RE"
+)
+
+// ParseSupplementaryData splits the trailing elements of a data line, nil when s holds no element
+func ParseSupplementaryData(s string) *SupplementaryData {
+	data := &SupplementaryData{}
+	empty := true
+	for _, element := range strings.Split(s, "/") {
+		element = strings.TrimSpace(element)
+		if element != "" {
+			data.add(element)
+			empty = false
+		}
+	}
+	if empty {
+		return nil
+	}
+	return data
+}
+
+// add decodes a single element, keeping it raw when the tag is unknown or the value does not fit
+func (d *SupplementaryData) add(element string) {
+	tag, value, _ := strings.Cut(element, ".")
+	switch {
+	case tag == supplementaryCoordinatorReference && value != "" && d.CoordinatorReference == "":
+		d.CoordinatorReference = value
+	case tag == supplementaryPreviousStation && isStationCode(value) && d.PreviousStation == "":
+		d.PreviousStation = value
+	case tag == supplementaryNextStation && isStationCode(value) && d.NextStation == "":
+		d.NextStation = value
+	case tag == supplementaryRemark && value != "":
+		d.Remarks = append(d.Remarks, value)
+	default:
+		d.Unknown = append(d.Unknown, element)
+	}
+}
+
+// Elements returns the elements in the order String writes them e.g. "CR.KRK000123", "RE.SUBJECT TO APRON CAPACITY"
+func (d SupplementaryData) Elements() []string {
+	elements := make([]string, 0, len(d.Remarks)+len(d.Unknown)+3)
+	if d.CoordinatorReference != "" {
+		elements = append(elements, supplementaryCoordinatorReference+"."+d.CoordinatorReference)
+	}
+	if d.PreviousStation != "" {
+		elements = append(elements, supplementaryPreviousStation+"."+d.PreviousStation)
+	}
+	if d.NextStation != "" {
+		elements = append(elements, supplementaryNextStation+"."+d.NextStation)
+	}
+	for _, remark := range d.Remarks {
+		elements = append(elements, supplementaryRemark+"."+remark)
+	}
+	return append(elements, d.Unknown...)
+}
+
+// Tagged returns the values of the raw elements written as <tag>.<value> in order,
+// e.g. Tagged("SI") gives "APRON 3" for the element SI.APRON 3
+func (d SupplementaryData) Tagged(tag string) []string {
+	values := make([]string, 0)
+	for _, element := range d.Unknown {
+		if value, found := strings.CutPrefix(element, tag+"."); found {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// String writes the elements in data line form e.g. "/ CR.KRK000123/ RE.SUBJECT TO APRON CAPACITY/"
+func (d SupplementaryData) String() string {
+	elements := d.Elements()
+	if len(elements) == 0 {
+		return ""
+	}
+	return "/ " + strings.Join(elements, "/ ") + "/"
+}
+
+// mergeSupplementary joins the elements of both turnaround halves for their shared data line.
+// Elements of the arrival come first, an element written on both halves is kept once.
+func mergeSupplementary(arrival, departure *SupplementaryData) *SupplementaryData {
+	if arrival == nil {
+		return departure
+	}
+	if departure == nil {
+		return arrival
+	}
+	merged := &SupplementaryData{}
+	for _, element := range slices.Concat(arrival.Elements(), departure.Elements()) {
+		if !slices.Contains(merged.Elements(), element) {
+			merged.add(element)
+		}
+	}
+	return merged
+}
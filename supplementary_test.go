@@ -0,0 +1,43 @@
+package ssimparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSupplementaryData(t *testing.T) {
+	data := ParseSupplementaryData("/ CR.KRK000123/ RE.SUBJECT TO APRON CAPACITY/ PS.NRT/ RE.NO PUSHBACK/ 12AB/ SI.APRON 3/ NS.HKG/")
+	if data == nil {
+		t.Fatal("ParseSupplementaryData returned nil")
+	}
+	want := &SupplementaryData{
+		CoordinatorReference: "KRK000123",
+		PreviousStation:      "NRT",
+		NextStation:          "HKG",
+		Remarks:              []string{"SUBJECT TO APRON CAPACITY", "NO PUSHBACK"},
+		Unknown:              []string{"12AB", "SI.APRON 3"},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("ParseSupplementaryData = %+v, want %+v", data, want)
+	}
+	if got := data.Tagged("SI"); !reflect.DeepEqual(got, []string{"APRON 3"}) {
+		t.Errorf("Tagged(SI) = %q", got)
+	}
+	if got := data.Tagged("RE"); len(got) != 0 {
+		t.Errorf("Tagged(RE) = %q, want none as remarks are decoded", got)
+	}
+	if got := data.String(); got != "/ CR.KRK000123/ PS.NRT/ NS.HKG/ RE.SUBJECT TO APRON CAPACITY/ RE.NO PUSHBACK/ 12AB/ SI.APRON 3/" {
+		t.Errorf("String() = %q", got)
+	}
+	if ParseSupplementaryData(" / / ") != nil {
+		t.Error("ParseSupplementaryData without elements should return nil")
+	}
+}
+
+func TestParseSupplementaryDataKeepsUndecodableRaw(t *testing.T) {
+	data := ParseSupplementaryData("/ PS.N1/ CR.A1/ CR.B2/")
+	want := &SupplementaryData{CoordinatorReference: "A1", Unknown: []string{"PS.N1", "CR.B2"}}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("ParseSupplementaryData = %+v, want %+v", data, want)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -22,11 +23,18 @@ SI HAPPYEASTERACKACK
 
 	// Option 1: Create parser with embedded validator
 	scr := ssimparser.NewScrParserWithValidator()
-	message, parserError := scr.Parse(strings.NewReader(testScrMessage))
+	message, err := scr.Parse(strings.NewReader(testScrMessage))
 
 	// Handle critical parsing errors (these stop parsing)
-	if parserError != nil && parserError.Err != nil {
-		fmt.Println(parserError.Error())
+	if err != nil {
+		var parserError *ssimparser.ParserError
+		if errors.As(err, &parserError) {
+			fmt.Printf("line %d: %s\n", parserError.LineNumber, parserError.RawLine)
+		}
+		if errors.Is(err, ssimparser.ErrBadPeriod) {
+			fmt.Println("check the period of operation")
+		}
+		fmt.Println(err.Error())
 		os.Exit(1)
 	}
 